@@ -2,6 +2,7 @@ package constant
 
 const (
 	Active                  = "active"
+	Deactivated             = "deactivated"
 	Accept                  = "Accept"
 	ApplicationJSON         = "application/json"
 	Authorization           = "Authorization"
@@ -21,6 +22,41 @@ const (
 	Wallet                  = "wallet"
 	Credit                  = "credit"
 	Debit                   = "debit"
+	System                  = "system"
+	PlatformWallet          = "platform"
+	ClearingWallet          = "clearing"
+	Unpaid                  = "unpaid"
+	Paid                    = "paid"
+	Fake                    = "fake"
+	PaymentSignatureHeader  = "X-Payment-Signature"
+	ChargeSuccess           = "charge.success"
+	ChargeFailed            = "charge.failed"
+	Refunded                = "refunded"
+	Backorder               = "backorder"
+	Provider                = "provider"
+	Invoice                 = "invoice"
+	Receipt                 = "receipt"
+	Sale                    = "sale"
+	Adjustment              = "adjustment"
+	Return                  = "return"
+	Damage                  = "damage"
+	OpeningBalance          = "opening_balance"
+	LowStock                = "low_stock"
+	OutOfStock              = "out_of_stock"
+	Created                 = "created"
+	Updated                 = "updated"
+	CSV                     = ".csv"
+	XLSX                    = ".xlsx"
+	Newest                  = "newest"
+	PriceAsc                = "price_asc"
+	PriceDesc               = "price_desc"
+	Rating                  = "rating"
+	Popularity              = "popularity"
+	Published               = "published"
+	Hidden                  = "hidden"
+	Publish                 = "publish"
+	Hide                    = "hide"
+	VatRate                 = 7.5
 	Delivered               = "delivered"
 	SMS                     = "sms"
 	NigeriaMSISDNPrefix     = "234"
@@ -38,6 +74,17 @@ const (
 	Approve                 = "approve"
 	Reject                  = "reject"
 	Rejected                = "rejected"
+	Prepayment              = "prepayment"
+	PayOnDelivery           = "pay_on_delivery"
+	CustomerPickUp          = "customer_pick_up"
+	Both                    = "both"
+	Confirmed               = "confirmed"
+	Cancelled               = "cancelled"
+	Cancel                  = "cancel"
+	Complete                = "complete"
+	Open                    = "open"
+	Accepted                = "accepted"
+	Expired                 = "expired"
 
 	DLQ                              = "dlq"
 	Delivery                         = "delivery"
@@ -52,6 +99,8 @@ const (
 	RateBackgroundWorkerLock         = "rate_background_worker_lock"
 	DispatchWebhookRetriesLock       = "dispatch_webhook_retries_lock"
 	DailyStatsLock                   = "daily_stats_lock"
+	StockAlertLock                   = "stock_alert_lock"
+	ProductImportLock                = "product_import_lock"
 	ErrorLogsDir                     = "./logs/errorlogs"
 	RequestLogsDir                   = "./logs/requestlogs"
 	Requests                         = "requests"
@@ -84,6 +133,9 @@ const (
 	Unverified           = "unverified"
 	RegisterOtp          = "register_otp"
 	ForgotPassword       = "forgot_password"
+	DeliveryOtp          = "delivery_otp"
+	DeliveryOtpAttempts  = "delivery_otp_attempts"
+	DeliveryOtpLockouts  = "delivery_otp_lockouts"
 	LoginAttempts        = "login_attempts"
 	LoginLockouts        = "login_lockouts"
 	Token                = "token"
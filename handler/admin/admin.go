@@ -3,9 +3,16 @@ package admin
 import (
 	"bambamload/constant"
 	"bambamload/handler"
+	"bambamload/logger"
 	"bambamload/models"
 	"bambamload/utils"
+	"bufio"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	f "github.com/gofiber/fiber/v2"
 )
@@ -230,11 +237,18 @@ func (h *Handler) GetProducts(c *f.Ctx) error {
 	page := c.Query(constant.Page, "1")
 	pageSize := c.Query(constant.PageSize, "10")
 	pm := utils.InitPaginationMetadata(page, pageSize)
-	searchText := c.Query("search_text", "")
-	status := c.Query("status", "")
-	productType := c.Query("type", "")
+	filter := models.ProductFilter{
+		Status:     c.Query("status", ""),
+		SearchText: c.Query("search_text", ""),
+		Type:       c.Query("type", ""),
+	}
+
+	products, paginationMeta, err := h.SupplierService.GetProducts(pm, filter)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
+	}
 
-	products, paginationMeta, err := h.SupplierService.GetProducts(pm, status, searchText, productType)
+	facets, err := h.SupplierService.GetProductFacets(filter)
 	if err != nil {
 		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
 	}
@@ -242,9 +256,38 @@ func (h *Handler) GetProducts(c *f.Ctx) error {
 	resp := map[string]interface{}{
 		"pagination_meta": paginationMeta,
 		"products":        products,
+		"facets":          facets,
 	}
 	return utils.WriteResponse(c, http.StatusOK, true, "success", resp)
 }
+
+// ExportProducts streams the filtered product catalog, with supplier details, as a CSV or XLSX download
+func (h *Handler) ExportProducts(c *f.Ctx) error {
+	_ = c.Locals("user").(*models.User)
+
+	ext := "." + strings.ToLower(c.Query("format", "csv"))
+	if ext != constant.CSV && ext != constant.XLSX {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "format can only be csv/xlsx", nil)
+	}
+	filter := models.ProductFilter{
+		Status:     c.Query("status", ""),
+		SearchText: c.Query("search_text", ""),
+		Type:       c.Query("type", ""),
+	}
+
+	c.Set(constant.ContentType, utils.ExtensionToContentType[ext])
+	c.Set(f.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="products_%s%s"`, time.Now().Format("20060102150405"), ext))
+
+	//the response has started by the time the export runs, so a failure part way can only be logged
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := h.AdminService.ExportProducts(w, ext, filter); err != nil {
+			logger.Logger.Errorf("[ExportProducts]export stopped: %v", err)
+		}
+		_ = w.Flush()
+	})
+	return nil
+}
+
 func (h *Handler) ApproveOrRejectSupplierProduct(c *f.Ctx) error {
 
 	user := c.Locals("user").(*models.User)
@@ -267,3 +310,236 @@ func (h *Handler) ApproveOrRejectSupplierProduct(c *f.Ctx) error {
 	}
 	return utils.WriteResponse(c, http.StatusOK, true, "success", nil)
 }
+
+func (h *Handler) GetOrders(c *f.Ctx) error {
+	_ = c.Locals("user").(*models.User)
+
+	page := c.Query(constant.Page, "1")
+	pageSize := c.Query(constant.PageSize, "10")
+	pm := utils.InitPaginationMetadata(page, pageSize)
+
+	filter := models.OrderFilter{
+		Status:     c.Query("status", ""),
+		SupplierID: c.Query("supplier_id", ""),
+		BuyerID:    c.Query("buyer_id", ""),
+	}
+
+	from, to, err := dateRangeQuery(c)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+	filter.From, filter.To = from, to
+	if minAmount := c.Query("min_amount", ""); minAmount != "" {
+		amount, err := strconv.ParseInt(minAmount, 10, 64)
+		if err != nil {
+			return utils.WriteResponse(c, http.StatusBadRequest, false, "min_amount must be a number", nil)
+		}
+		filter.MinAmount = amount
+	}
+	if maxAmount := c.Query("max_amount", ""); maxAmount != "" {
+		amount, err := strconv.ParseInt(maxAmount, 10, 64)
+		if err != nil {
+			return utils.WriteResponse(c, http.StatusBadRequest, false, "max_amount must be a number", nil)
+		}
+		filter.MaxAmount = amount
+	}
+
+	orders, paginationMeta, err := h.AdminService.GetOrders(pm, filter)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
+	}
+
+	resp := map[string]interface{}{
+		"pagination_meta": paginationMeta,
+		"orders":          orders,
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", resp)
+}
+
+func (h *Handler) GetOrder(c *f.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "id is required", nil)
+	}
+
+	order, err := h.AdminService.GetOrder(id)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", order)
+}
+
+func (h *Handler) OverrideOrderStatus(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	var req models.OverrideOrderStatusRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "invalid request body", nil)
+	}
+	id := c.Params("id")
+	if id == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "id is required", nil)
+	}
+	if req.Action != constant.Cancel && req.Action != constant.Complete {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "action can only be cancel/complete", nil)
+	}
+	if req.Reason == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "reason is required", nil)
+	}
+
+	refund, err := h.AdminService.OverrideOrderStatus(id, req, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+
+	//the order stays cancelled if the provider refund fails, admins can retry it from the refunds list
+	if refund != nil {
+		if err = h.UtilitiesService.ProcessRefund(refund); err != nil {
+			logger.Logger.Errorf("[OverrideOrderStatus]Process refund error: %v", err)
+		}
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", refund)
+}
+
+func (h *Handler) GetRefunds(c *f.Ctx) error {
+	page := c.Query(constant.Page, "1")
+	pageSize := c.Query(constant.PageSize, "10")
+	pm := utils.InitPaginationMetadata(page, pageSize)
+
+	filter := models.RefundFilter{
+		Status: c.Query("status", ""),
+		Method: c.Query("method", ""),
+	}
+
+	from, to, err := dateRangeQuery(c)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+	filter.From, filter.To = from, to
+
+	refunds, paginationMeta, err := h.AdminService.GetRefunds(pm, filter)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
+	}
+
+	resp := map[string]interface{}{
+		"pagination_meta": paginationMeta,
+		"refunds":         refunds,
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", resp)
+}
+
+func (h *Handler) GetRefund(c *f.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "id is required", nil)
+	}
+
+	refund, err := h.AdminService.GetRefund(id)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", refund)
+}
+
+func (h *Handler) RetryRefund(c *f.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "id is required", nil)
+	}
+
+	refund, err := h.AdminService.GetRefund(id)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+	if refund.Method != constant.Provider || refund.Status != constant.Failed {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "only failed provider refunds can be retried", nil)
+	}
+
+	if err = h.UtilitiesService.ProcessRefund(refund); err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", refund)
+}
+
+func (h *Handler) ResetRefund(c *f.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "id is required", nil)
+	}
+
+	refund, err := h.AdminService.ResetRefund(id)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", refund)
+}
+
+func (h *Handler) GetSettlements(c *f.Ctx) error {
+	page := c.Query(constant.Page, "1")
+	pageSize := c.Query(constant.PageSize, "10")
+	pm := utils.InitPaginationMetadata(page, pageSize)
+
+	filter := models.SettlementFilter{
+		SupplierID: c.Query("supplier_id", ""),
+		Status:     c.Query("status", ""),
+	}
+
+	from, to, err := dateRangeQuery(c)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+	filter.From, filter.To = from, to
+
+	resp, err := h.AdminService.GetSettlements(pm, filter)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", resp)
+}
+
+// dateRangeQuery reads the optional from/to (YYYY-MM-DD) query params. The returned upper bound is exclusive.
+func dateRangeQuery(c *f.Ctx) (time.Time, time.Time, error) {
+	var from, to time.Time
+
+	if fromString := c.Query("from", ""); fromString != "" {
+		fromDate, err := utils.DateStringToTime("2006-01-02", fromString)
+		if err != nil {
+			return from, to, errors.New("invalid from date format. Use YYYY-MM-DD")
+		}
+		from = fromDate
+	}
+	if toString := c.Query("to", ""); toString != "" {
+		toDate, err := utils.DateStringToTime("2006-01-02", toString)
+		if err != nil {
+			return from, to, errors.New("invalid to date format. Use YYYY-MM-DD")
+		}
+		to = toDate.AddDate(0, 0, 1)
+	}
+	return from, to, nil
+}
+
+func (h *Handler) GetOrderDocument(c *f.Ctx) error {
+	_ = c.Locals("user").(*models.User)
+
+	id := c.Params("id")
+	if id == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "id cannot be empty", nil)
+	}
+	docType := c.Params("type")
+	if docType != constant.Invoice && docType != constant.Receipt {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "document type can only be invoice/receipt", nil)
+	}
+
+	order, err := h.AdminService.GetOrder(id)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+
+	document, err := h.UtilitiesService.GetOrderDocument(order, docType)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", document)
+}
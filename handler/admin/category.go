@@ -0,0 +1,112 @@
+package admin
+
+import (
+	"bambamload/constant"
+	"bambamload/models"
+	"bambamload/utils"
+	"net/http"
+	"strings"
+
+	f "github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) CreateCategory(c *f.Ctx) error {
+	_ = c.Locals("user").(*models.User)
+	var req models.CategoryRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "invalid request body", nil)
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "name cannot be empty", nil)
+	}
+	if req.Status != "" && req.Status != constant.Active && req.Status != constant.Deactivated {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "status can only be active/deactivated", nil)
+	}
+
+	category, err := h.AdminService.CreateCategory(req)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", category)
+}
+
+func (h *Handler) UpdateCategory(c *f.Ctx) error {
+	_ = c.Locals("user").(*models.User)
+	var req models.CategoryRequest
+
+	id := c.Params("id")
+	if id == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "id cannot be empty", nil)
+	}
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "invalid request body", nil)
+	}
+	if req.Status != "" && req.Status != constant.Active && req.Status != constant.Deactivated {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "status can only be active/deactivated", nil)
+	}
+
+	category, err := h.AdminService.UpdateCategory(id, req)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", category)
+}
+
+func (h *Handler) DeleteCategory(c *f.Ctx) error {
+	_ = c.Locals("user").(*models.User)
+
+	id := c.Params("id")
+	if id == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "id cannot be empty", nil)
+	}
+
+	if err := h.AdminService.DeleteCategory(id); err != nil {
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", nil)
+}
+
+// GetCategories returns the whole category tree, or only active categories with ?status=active
+func (h *Handler) GetCategories(c *f.Ctx) error {
+	_ = c.Locals("user").(*models.User)
+
+	activeOnly := c.Query("status", "") == constant.Active
+
+	categories, err := h.UtilitiesService.GetCategoryTree(activeOnly)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", categories)
+}
+
+// GetUnmappedCategories lists the free-text categories of products not yet filed in the category tree
+func (h *Handler) GetUnmappedCategories(c *f.Ctx) error {
+	_ = c.Locals("user").(*models.User)
+
+	unmapped, err := h.AdminService.GetUnmappedCategories()
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", unmapped)
+}
+
+// MapCategories files products with free-text categories under categories in the tree
+func (h *Handler) MapCategories(c *f.Ctx) error {
+	_ = c.Locals("user").(*models.User)
+	var req models.MapCategoriesRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "invalid request body", nil)
+	}
+	if len(req.Mappings) == 0 {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "mappings cannot be empty", nil)
+	}
+
+	results := h.AdminService.MapCategories(req)
+	return utils.WriteResponse(c, http.StatusOK, true, "success", results)
+}
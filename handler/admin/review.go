@@ -0,0 +1,60 @@
+package admin
+
+import (
+	"bambamload/constant"
+	"bambamload/models"
+	"bambamload/utils"
+	"net/http"
+
+	f "github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) GetReviews(c *f.Ctx) error {
+	_ = c.Locals("user").(*models.User)
+
+	page := c.Query(constant.Page, "1")
+	pageSize := c.Query(constant.PageSize, "10")
+	pm := utils.InitPaginationMetadata(page, pageSize)
+	filter := models.ReviewFilter{
+		ProductID: c.Query("product_id", ""),
+		Status:    c.Query("status", ""),
+	}
+
+	reviews, paginationMeta, err := h.AdminService.GetReviews(pm, filter)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
+	}
+
+	resp := map[string]interface{}{
+		"pagination_meta": paginationMeta,
+		"reviews":         reviews,
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", resp)
+}
+
+func (h *Handler) ModerateReview(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	var req models.ModerateReviewRequest
+
+	id := c.Params("id")
+	if id == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "id cannot be empty", nil)
+	}
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "invalid request body", nil)
+	}
+	if req.Action != constant.Hide && req.Action != constant.Publish {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "action can only be hide/publish", nil)
+	}
+	if req.Action == constant.Hide && req.Reason == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "reason cannot be empty", nil)
+	}
+
+	review, err := h.AdminService.ModerateReview(id, req, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", review)
+}
@@ -57,7 +57,7 @@ func (h *Handler) Register(c *f.Ctx) error {
 	sEmail := strings.ToLower(strings.TrimSpace(req.Email))
 
 	//trigger otp after registration
-	err = h.UtilitiesService.SendOtp(constant.RegisterOtp, sEmail)
+	err = h.UtilitiesService.SendOtp(constant.RegisterOtp, sEmail, nil)
 	if err != nil {
 		logger.Logger.Errorf("[Register]Send Otp error: %v", err)
 	}
@@ -0,0 +1,89 @@
+package buyer
+
+import (
+	"bambamload/models"
+	"bambamload/utils"
+	"net/http"
+
+	f "github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) GetCart(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
+	cart, err := h.BuyerService.GetCart(user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", cart)
+}
+
+func (h *Handler) AddCartItem(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	var req models.AddCartItemRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "invalid request body", nil)
+	}
+	if req.ProductID == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "product id is required", nil)
+	}
+	if req.Quantity <= 0 {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "quantity must be greater than zero", nil)
+	}
+
+	cart, err := h.BuyerService.AddCartItem(req, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", cart)
+}
+
+func (h *Handler) UpdateCartItem(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	var req models.UpdateCartItemRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "invalid request body", nil)
+	}
+	id := c.Params("id")
+	if id == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "id cannot be empty", nil)
+	}
+	if req.Quantity <= 0 {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "quantity must be greater than zero", nil)
+	}
+
+	cart, err := h.BuyerService.UpdateCartItem(id, req, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", cart)
+}
+
+func (h *Handler) RemoveCartItem(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
+	id := c.Params("id")
+	if id == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "id cannot be empty", nil)
+	}
+
+	cart, err := h.BuyerService.RemoveCartItem(id, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", cart)
+}
+
+func (h *Handler) ClearCart(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
+	err := h.BuyerService.ClearCart(user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", nil)
+}
@@ -0,0 +1,186 @@
+package buyer
+
+import (
+	"bambamload/constant"
+	"bambamload/logger"
+	"bambamload/models"
+	"bambamload/utils"
+	"net/http"
+
+	f "github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) Checkout(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	var req models.CheckoutRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "invalid request body", nil)
+	}
+	if req.PaymentTerms != constant.Prepayment && req.PaymentTerms != constant.PayOnDelivery {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "payment_terms can only be prepayment/pay_on_delivery", nil)
+	}
+	if req.FulfilmentType != constant.Delivery && req.FulfilmentType != constant.CustomerPickUp {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "fulfilment_type can only be delivery/customer_pick_up", nil)
+	}
+	if req.FulfilmentType == constant.Delivery && req.DeliveryAddress == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "delivery address is required", nil)
+	}
+
+	resp, err := h.BuyerService.Checkout(req, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "checkout successful", resp)
+}
+
+func (h *Handler) ReserveCheckout(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
+	reservation, err := h.BuyerService.ReserveCheckout(user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "stock reserved", reservation)
+}
+
+func (h *Handler) ReleaseCheckout(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
+	if err := h.BuyerService.ReleaseCheckout(user); err != nil {
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "reservation released", nil)
+}
+
+func (h *Handler) GetOrders(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
+	page := c.Query(constant.Page, "1")
+	pageSize := c.Query(constant.PageSize, "10")
+	pm := utils.InitPaginationMetadata(page, pageSize)
+	status := c.Query("status", "")
+
+	orders, paginationMeta, err := h.BuyerService.GetOrders(pm, status, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
+	}
+
+	resp := map[string]interface{}{
+		"pagination_meta": paginationMeta,
+		"orders":          orders,
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", resp)
+}
+
+func (h *Handler) GetOrder(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
+	id := c.Params("id")
+	if id == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "id cannot be empty", nil)
+	}
+
+	order, err := h.BuyerService.GetOrder(id, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", order)
+}
+
+func (h *Handler) ConfirmOrderReceipt(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	var req models.OrderActionRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "invalid request body", nil)
+	}
+	id := c.Params("id")
+	if id == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "id cannot be empty", nil)
+	}
+
+	err := h.BuyerService.ConfirmOrderReceipt(id, req.Note, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", nil)
+}
+
+func (h *Handler) CancelOrder(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	var req models.CancelOrderRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "invalid request body", nil)
+	}
+	id := c.Params("id")
+	if id == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "id cannot be empty", nil)
+	}
+	if req.Reason == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "reason is required", nil)
+	}
+
+	refund, err := h.BuyerService.CancelOrder(id, req, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+
+	//the order stays cancelled if the provider refund fails, the refund is retried by an admin
+	if refund != nil {
+		if err = h.UtilitiesService.ProcessRefund(refund); err != nil {
+			logger.Logger.Errorf("[CancelOrder]Process refund error: %v", err)
+		}
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", refund)
+}
+
+func (h *Handler) ResendDeliveryCode(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
+	id := c.Params("id")
+	if id == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "id cannot be empty", nil)
+	}
+
+	order, err := h.BuyerService.GetOrder(id, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+	if order.PaymentTerms != constant.PayOnDelivery || order.Status != constant.InTransit {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "delivery codes are only issued for pay on delivery orders in transit", nil)
+	}
+
+	if err = h.UtilitiesService.SendOtp(constant.DeliveryOtp, order.Buyer.Email, order); err != nil {
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, "unable to send delivery code, please try again", nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "successful", nil)
+}
+
+func (h *Handler) GetOrderDocument(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
+	id := c.Params("id")
+	if id == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "id cannot be empty", nil)
+	}
+	docType := c.Params("type")
+	if docType != constant.Invoice && docType != constant.Receipt {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "document type can only be invoice/receipt", nil)
+	}
+
+	order, err := h.BuyerService.GetOrder(id, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+
+	document, err := h.UtilitiesService.GetOrderDocument(order, docType)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", document)
+}
@@ -0,0 +1,42 @@
+package buyer
+
+import (
+	"bambamload/models"
+	"bambamload/utils"
+	"net/http"
+
+	f "github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) InitializeCheckoutPayment(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
+	reference := c.Params("reference")
+	if reference == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "checkout reference is required", nil)
+	}
+
+	payment, err := h.BuyerService.InitializeCheckoutPayment(reference, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", payment)
+}
+
+func (h *Handler) VerifyPayment(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
+	reference := c.Params("reference")
+	if reference == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "reference is required", nil)
+	}
+
+	payment, err := h.BuyerService.VerifyPayment(reference, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+
+	//orders closed while the payment was in progress are refunded
+	h.UtilitiesService.ProcessPaymentRefunds(payment.Reference)
+	return utils.WriteResponse(c, http.StatusOK, true, "success", payment)
+}
@@ -5,34 +5,86 @@ import (
 	"bambamload/models"
 	"bambamload/utils"
 	"net/http"
+	"strconv"
 
 	f "github.com/gofiber/fiber/v2"
 )
 
 func (h *Handler) GetProduct(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
 	id := c.Params("id")
 	if id == "" {
 		return utils.WriteResponse(c, http.StatusBadRequest, false, "id cannot be empty", nil)
 	}
 
-	product, err := h.SupplierService.SupplierGetProduct(id, nil)
+	quantity, _ := strconv.ParseInt(c.Query("quantity", "0"), 10, 64)
+
+	product, err := h.BuyerService.GetProduct(id, quantity, user)
 	if err != nil {
-		return utils.WriteResponse(c, http.StatusInternalServerError, false, "get product failed", nil)
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
 	}
 	return utils.WriteResponse(c, http.StatusOK, true, "success", product)
 }
 
 func (h *Handler) GetProducts(c *f.Ctx) error {
-	_ = c.Locals("user").(*models.User)
+	user := c.Locals("user").(*models.User)
 
 	page := c.Query(constant.Page, "1")
 	pageSize := c.Query(constant.PageSize, "10")
 	pm := utils.InitPaginationMetadata(page, pageSize)
-	searchText := c.Query("search_text", "")
-	status := c.Query("status", "")
-	productType := c.Query("type", "")
+	filter := models.ProductFilter{
+		SearchText:     c.Query("search_text", ""),
+		Type:           c.Query("type", ""),
+		FulfilmentType: c.Query("fulfilment_type", ""),
+		PaymentTerms:   c.Query("payment_terms", ""),
+		SupplierState:  c.Query("state", ""),
+		SupplierRegion: c.Query("region", ""),
+		InStockOnly:    c.QueryBool("in_stock", false),
+		Sort:           c.Query("sort", ""),
+	}
+	quantity, _ := strconv.ParseInt(c.Query("quantity", "0"), 10, 64)
+
+	for _, param := range []struct {
+		name  string
+		value *int64
+	}{
+		{"min_price", &filter.MinPrice},
+		{"max_price", &filter.MaxPrice},
+		{"max_minimum_order_quantity", &filter.MaxMinimumOrderQuantity},
+	} {
+		raw := c.Query(param.name, "")
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || value < 0 {
+			return utils.WriteResponse(c, http.StatusBadRequest, false, param.name+" must be a whole number that is not negative", nil)
+		}
+		*param.value = value
+	}
+	if filter.MaxPrice > 0 && filter.MinPrice > filter.MaxPrice {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "min_price cannot be greater than max_price", nil)
+	}
+
+	if filter.FulfilmentType != "" && filter.FulfilmentType != constant.Delivery &&
+		filter.FulfilmentType != constant.CustomerPickUp && filter.FulfilmentType != constant.Both {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "fulfilment_type can only be delivery/customer_pick_up/both", nil)
+	}
+	if filter.PaymentTerms != "" && filter.PaymentTerms != constant.Prepayment && filter.PaymentTerms != constant.PayOnDelivery {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "payment_terms can only be prepayment/pay_on_delivery", nil)
+	}
+	if filter.Sort != "" && filter.Sort != constant.Newest && filter.Sort != constant.PriceAsc && filter.Sort != constant.PriceDesc &&
+		filter.Sort != constant.Rating && filter.Sort != constant.Popularity {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "sort can only be newest/price_asc/price_desc/rating/popularity", nil)
+	}
 
-	products, paginationMeta, err := h.SupplierService.GetProducts(pm, status, searchText, productType)
+	products, paginationMeta, err := h.BuyerService.GetProducts(pm, filter, quantity, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
+	}
+
+	facets, err := h.BuyerService.GetProductFacets(filter)
 	if err != nil {
 		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
 	}
@@ -40,6 +92,18 @@ func (h *Handler) GetProducts(c *f.Ctx) error {
 	resp := map[string]interface{}{
 		"pagination_meta": paginationMeta,
 		"products":        products,
+		"facets":          facets,
 	}
 	return utils.WriteResponse(c, http.StatusOK, true, "success", resp)
 }
+
+// GetCategories returns the tree of categories products can be filed under
+func (h *Handler) GetCategories(c *f.Ctx) error {
+	_ = c.Locals("user").(*models.User)
+
+	categories, err := h.UtilitiesService.GetCategoryTree(true)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", categories)
+}
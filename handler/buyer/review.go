@@ -0,0 +1,64 @@
+package buyer
+
+import (
+	"bambamload/constant"
+	"bambamload/models"
+	"bambamload/utils"
+	"net/http"
+
+	f "github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) CreateReview(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	var req models.CreateReviewRequest
+
+	id := c.Params("id")
+	if id == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "id cannot be empty", nil)
+	}
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "invalid request body", nil)
+	}
+	if req.OrderID == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "order_id cannot be empty", nil)
+	}
+	if req.Rating < 1 || req.Rating > 5 {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "rating must be between 1 and 5", nil)
+	}
+	if len(req.Comment) > 2000 {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "comment cannot be longer than 2000 characters", nil)
+	}
+
+	review, err := h.BuyerService.CreateReview(id, req, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", review)
+}
+
+func (h *Handler) GetProductReviews(c *f.Ctx) error {
+	_ = c.Locals("user").(*models.User)
+
+	id := c.Params("id")
+	if id == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "id cannot be empty", nil)
+	}
+
+	page := c.Query(constant.Page, "1")
+	pageSize := c.Query(constant.PageSize, "10")
+	pm := utils.InitPaginationMetadata(page, pageSize)
+
+	reviews, paginationMeta, err := h.BuyerService.GetProductReviews(pm, id)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
+	}
+
+	resp := map[string]interface{}{
+		"pagination_meta": paginationMeta,
+		"reviews":         reviews,
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", resp)
+}
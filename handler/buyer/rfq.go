@@ -0,0 +1,141 @@
+package buyer
+
+import (
+	"bambamload/constant"
+	"bambamload/models"
+	"bambamload/utils"
+	"net/http"
+
+	f "github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) CreateRfq(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	var req models.CreateRfqRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "invalid request body", nil)
+	}
+	if req.ProductID == "" && req.Category == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "product_id or category is required", nil)
+	}
+	if req.Quantity <= 0 {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "quantity must be greater than zero", nil)
+	}
+	if req.PaymentTerms != constant.Prepayment && req.PaymentTerms != constant.PayOnDelivery {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "payment_terms can only be prepayment/pay_on_delivery", nil)
+	}
+	if req.FulfilmentType != constant.Delivery && req.FulfilmentType != constant.CustomerPickUp {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "fulfilment_type can only be delivery/customer_pick_up", nil)
+	}
+	if req.FulfilmentType == constant.Delivery && req.DeliveryAddress == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "delivery address is required", nil)
+	}
+
+	rfq, err := h.BuyerService.CreateRfq(req, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", rfq)
+}
+
+func (h *Handler) GetRfqs(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
+	page := c.Query(constant.Page, "1")
+	pageSize := c.Query(constant.PageSize, "10")
+	pm := utils.InitPaginationMetadata(page, pageSize)
+	status := c.Query("status", "")
+
+	rfqs, paginationMeta, err := h.BuyerService.GetRfqs(pm, status, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
+	}
+
+	resp := map[string]interface{}{
+		"pagination_meta": paginationMeta,
+		"rfqs":            rfqs,
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", resp)
+}
+
+func (h *Handler) GetRfq(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
+	id := c.Params("id")
+	if id == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "id cannot be empty", nil)
+	}
+
+	rfq, err := h.BuyerService.GetRfq(id, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", rfq)
+}
+
+func (h *Handler) CancelRfq(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
+	id := c.Params("id")
+	if id == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "id cannot be empty", nil)
+	}
+
+	err := h.BuyerService.CancelRfq(id, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", nil)
+}
+
+func (h *Handler) CounterQuote(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	var req models.CounterOfferRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "invalid request body", nil)
+	}
+	id := c.Params("id")
+	if id == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "id cannot be empty", nil)
+	}
+
+	quote, err := h.BuyerService.CounterQuote(id, req, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", quote)
+}
+
+func (h *Handler) AcceptQuote(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
+	id := c.Params("id")
+	if id == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "id cannot be empty", nil)
+	}
+
+	resp, err := h.BuyerService.AcceptQuote(id, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "quote accepted", resp)
+}
+
+func (h *Handler) RejectQuote(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
+	id := c.Params("id")
+	if id == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "id cannot be empty", nil)
+	}
+
+	err := h.BuyerService.RejectQuote(id, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", nil)
+}
@@ -0,0 +1,74 @@
+package supplier
+
+import (
+	"bambamload/constant"
+	"bambamload/logger"
+	"bambamload/models"
+	"bambamload/utils"
+	"io"
+	"net/http"
+
+	f "github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) ImportProducts(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "file cannot be empty", nil)
+	}
+
+	ff, err := file.Open()
+	if err != nil {
+		logger.Logger.Errorf("[ImportProducts]Open file error: %v", err)
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "unable to read file", nil)
+	}
+	defer ff.Close()
+
+	data, err := io.ReadAll(ff)
+	if err != nil {
+		logger.Logger.Errorf("[ImportProducts]Read file error: %v", err)
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "unable to read file", nil)
+	}
+
+	productImport, err := h.SupplierService.ImportProducts(data, file.Filename, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusAccepted, true, "import started", productImport)
+}
+
+func (h *Handler) GetProductImports(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
+	page := c.Query(constant.Page, "1")
+	pageSize := c.Query(constant.PageSize, "10")
+	pm := utils.InitPaginationMetadata(page, pageSize)
+
+	imports, paginationMeta, err := h.SupplierService.GetProductImports(pm, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
+	}
+
+	resp := map[string]interface{}{
+		"pagination_meta": paginationMeta,
+		"imports":         imports,
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", resp)
+}
+
+func (h *Handler) GetProductImport(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
+	id := c.Params("id")
+	if id == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "id cannot be empty", nil)
+	}
+
+	productImport, err := h.SupplierService.GetProductImport(id, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", productImport)
+}
@@ -0,0 +1,45 @@
+package supplier
+
+import (
+	"bambamload/constant"
+	"bambamload/models"
+	"bambamload/utils"
+	"net/http"
+
+	f "github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) GetNotifications(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
+	page := c.Query(constant.Page, "1")
+	pageSize := c.Query(constant.PageSize, "10")
+	pm := utils.InitPaginationMetadata(page, pageSize)
+	unreadOnly := c.QueryBool("unread", false)
+
+	notifications, paginationMeta, err := h.UtilitiesService.GetNotifications(pm, unreadOnly, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
+	}
+
+	resp := map[string]interface{}{
+		"pagination_meta": paginationMeta,
+		"notifications":   notifications,
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", resp)
+}
+
+func (h *Handler) MarkNotificationsRead(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	var req models.MarkNotificationsReadRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "invalid request body", nil)
+	}
+
+	if err := h.UtilitiesService.MarkNotificationsRead(req.IDs, user); err != nil {
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", nil)
+}
@@ -0,0 +1,238 @@
+package supplier
+
+import (
+	"bambamload/constant"
+	"bambamload/logger"
+	"bambamload/models"
+	"bambamload/utils"
+	"net/http"
+
+	f "github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) GetOrders(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
+	page := c.Query(constant.Page, "1")
+	pageSize := c.Query(constant.PageSize, "10")
+	pm := utils.InitPaginationMetadata(page, pageSize)
+	status := c.Query("status", "")
+
+	orders, paginationMeta, err := h.SupplierService.GetOrders(pm, status, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
+	}
+
+	resp := map[string]interface{}{
+		"pagination_meta": paginationMeta,
+		"orders":          orders,
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", resp)
+}
+
+func (h *Handler) GetOrder(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
+	id := c.Params("id")
+	if id == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "id cannot be empty", nil)
+	}
+
+	order, err := h.SupplierService.GetOrder(id, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", order)
+}
+
+func (h *Handler) AcceptOrder(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	var req models.OrderActionRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "invalid request body", nil)
+	}
+	id := c.Params("id")
+	if id == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "id cannot be empty", nil)
+	}
+
+	err := h.SupplierService.AcceptOrder(id, req.Note, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", nil)
+}
+
+func (h *Handler) RejectOrder(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	var req models.RejectOrderRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "invalid request body", nil)
+	}
+	id := c.Params("id")
+	if id == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "id cannot be empty", nil)
+	}
+	if req.Reason == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "reason is required", nil)
+	}
+
+	refund, err := h.SupplierService.RejectOrder(id, req.Reason, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+
+	if refund != nil {
+		if err = h.UtilitiesService.ProcessRefund(refund); err != nil {
+			logger.Logger.Errorf("[RejectOrder]Process refund error: %v", err)
+		}
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", nil)
+}
+
+func (h *Handler) CancelOrder(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	var req models.CancelOrderRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "invalid request body", nil)
+	}
+	id := c.Params("id")
+	if id == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "id cannot be empty", nil)
+	}
+	if req.Reason == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "reason is required", nil)
+	}
+
+	refund, err := h.SupplierService.CancelOrder(id, req.Reason, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+
+	//the order stays cancelled if the provider refund fails, admins can retry it from the refunds list
+	if refund != nil {
+		if err = h.UtilitiesService.ProcessRefund(refund); err != nil {
+			logger.Logger.Errorf("[CancelOrder]Process refund error: %v", err)
+		}
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", nil)
+}
+
+func (h *Handler) DispatchOrder(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	var req models.DispatchOrderRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "invalid request body", nil)
+	}
+	id := c.Params("id")
+	if id == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "id cannot be empty", nil)
+	}
+	if req.WaybillReference == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "waybill reference is required", nil)
+	}
+
+	order, err := h.SupplierService.DispatchOrder(id, req, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+
+	//the buyer hands this code to the driver once the goods and cash have changed hands
+	if order.PaymentTerms == constant.PayOnDelivery {
+		if err = h.UtilitiesService.SendOtp(constant.DeliveryOtp, order.Buyer.Email, order); err != nil {
+			logger.Logger.Errorf("[DispatchOrder]Send delivery otp error: %v", err)
+		}
+	}
+
+	return utils.WriteResponse(c, http.StatusOK, true, "success", nil)
+}
+
+func (h *Handler) ResolveUnfilledItems(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	var req models.ResolveUnfilledRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "invalid request body", nil)
+	}
+	id := c.Params("id")
+	if id == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "id cannot be empty", nil)
+	}
+	if len(req.Items) == 0 {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "items cannot be empty", nil)
+	}
+
+	err := h.SupplierService.ResolveUnfilledItems(id, req, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", nil)
+}
+
+func (h *Handler) ConfirmDelivery(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	var req models.ConfirmDeliveryRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "invalid request body", nil)
+	}
+	id := c.Params("id")
+	if id == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "id cannot be empty", nil)
+	}
+
+	order, err := h.SupplierService.GetOrder(id, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+
+	if order.PaymentTerms == constant.PayOnDelivery {
+		if req.Code == "" {
+			return utils.WriteResponse(c, http.StatusBadRequest, false, "delivery confirmation code is required", nil)
+		}
+		if err = h.UtilitiesService.VerifyOtp(constant.DeliveryOtp, req.Code, order.Buyer.Email, order); err != nil {
+			return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+		}
+	}
+
+	err = h.SupplierService.ConfirmDelivery(order, req.Note, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+
+	return utils.WriteResponse(c, http.StatusOK, true, "success", nil)
+}
+
+func (h *Handler) GetOrderDocument(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
+	id := c.Params("id")
+	if id == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "id cannot be empty", nil)
+	}
+	docType := c.Params("type")
+	if docType != constant.Invoice && docType != constant.Receipt {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "document type can only be invoice/receipt", nil)
+	}
+
+	order, err := h.SupplierService.GetOrder(id, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+
+	document, err := h.UtilitiesService.GetOrderDocument(order, docType)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", document)
+}
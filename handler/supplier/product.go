@@ -22,16 +22,16 @@ func (h *Handler) CreateProduct(c *f.Ctx) error {
 	if req.Name == "" {
 		return utils.WriteResponse(c, http.StatusBadRequest, false, "name cannot be empty", nil)
 	}
-	if req.Category == "" {
-		return utils.WriteResponse(c, http.StatusBadRequest, false, "category cannot be empty", nil)
+	if req.CategoryID == "" && req.Category == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "category_id or category cannot be empty", nil)
 	}
-	if req.Type == "" {
-		return utils.WriteResponse(c, http.StatusBadRequest, false, "phone type cannot be empty", nil)
+	if err := utils.ValidatePriceTiers(req.PriceTiers); err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
 	}
 
 	err := h.SupplierService.CreateProduct(&req, user)
 	if err != nil {
-		return utils.WriteResponse(c, http.StatusInternalServerError, false, "create product failed", nil)
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
 	}
 
 	return utils.WriteResponse(c, http.StatusOK, true, "create product successfully", nil)
@@ -67,13 +67,19 @@ func (h *Handler) GetProducts(c *f.Ctx) error {
 	page := c.Query(constant.Page, "1")
 	pageSize := c.Query(constant.PageSize, "10")
 	pm := utils.InitPaginationMetadata(page, pageSize)
-	searchText := c.Query("search_text", "")
-	status := c.Query("status", "")
-	productType := c.Query("type", "")
+	filter := models.ProductFilter{
+		SupplierID: user.ID,
+		Status:     c.Query("status", ""),
+		SearchText: c.Query("search_text", ""),
+		Type:       c.Query("type", ""),
+	}
 
-	pm.SupplierID = user.ID
+	products, paginationMeta, err := h.SupplierService.GetProducts(pm, filter)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
+	}
 
-	products, paginationMeta, err := h.SupplierService.GetProducts(pm, status, searchText, productType)
+	facets, err := h.SupplierService.GetProductFacets(filter)
 	if err != nil {
 		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
 	}
@@ -81,13 +87,14 @@ func (h *Handler) GetProducts(c *f.Ctx) error {
 	resp := map[string]interface{}{
 		"pagination_meta": paginationMeta,
 		"products":        products,
+		"facets":          facets,
 	}
 	return utils.WriteResponse(c, http.StatusOK, true, "success", resp)
 }
 
 func (h *Handler) EditProduct(c *f.Ctx) error {
 	user := c.Locals("user").(*models.User)
-	var req models.Product
+	var req models.EditProductRequest
 
 	// Parse request body
 	if err := c.BodyParser(&req); err != nil {
@@ -97,6 +104,9 @@ func (h *Handler) EditProduct(c *f.Ctx) error {
 	if id == "" {
 		return utils.WriteResponse(c, http.StatusBadRequest, false, "id cannot be empty", nil)
 	}
+	if err := utils.ValidatePriceTiers(req.PriceTiers); err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
 
 	err := h.SupplierService.EditProduct(id, &req, user)
 	if err != nil {
@@ -167,3 +177,103 @@ func (h *Handler) UploadProductImages(c *f.Ctx) error {
 
 	return utils.WriteResponse(c, http.StatusOK, true, "success", nil)
 }
+
+func (h *Handler) AddProductVariants(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	var req models.AddProductVariantsRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "invalid request body", nil)
+	}
+	id := c.Params("id")
+	if id == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "id cannot be empty", nil)
+	}
+	if len(req.Variants) == 0 {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "variants cannot be empty", nil)
+	}
+
+	err := h.SupplierService.AddProductVariants(id, req.Variants, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", nil)
+}
+
+func (h *Handler) EditProductVariant(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	var req models.EditProductVariantRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "invalid request body", nil)
+	}
+	id := c.Params("id")
+	variantID := c.Params("variant_id")
+	if id == "" || variantID == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "id cannot be empty", nil)
+	}
+
+	err := h.SupplierService.EditProductVariant(id, variantID, req, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", nil)
+}
+
+func (h *Handler) AdjustStock(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	var req models.StockAdjustmentRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "invalid request body", nil)
+	}
+	id := c.Params("id")
+	if id == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "id cannot be empty", nil)
+	}
+
+	movement, err := h.SupplierService.AdjustStock(id, req, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", movement)
+}
+
+func (h *Handler) GetStockMovements(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
+	id := c.Params("id")
+	if id == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "id cannot be empty", nil)
+	}
+
+	page := c.Query(constant.Page, "1")
+	pageSize := c.Query(constant.PageSize, "10")
+	pm := utils.InitPaginationMetadata(page, pageSize)
+	variantID := c.Query("variant_id", "")
+
+	movements, paginationMeta, err := h.SupplierService.GetStockMovements(pm, id, variantID, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
+	}
+
+	resp := map[string]interface{}{
+		"pagination_meta": paginationMeta,
+		"movements":       movements,
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", resp)
+}
+
+// GetCategories returns the tree of categories products can be filed under
+func (h *Handler) GetCategories(c *f.Ctx) error {
+	_ = c.Locals("user").(*models.User)
+
+	categories, err := h.UtilitiesService.GetCategoryTree(true)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", categories)
+}
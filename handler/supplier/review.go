@@ -0,0 +1,57 @@
+package supplier
+
+import (
+	"bambamload/constant"
+	"bambamload/models"
+	"bambamload/utils"
+	"net/http"
+	"strings"
+
+	f "github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) GetReviews(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
+	page := c.Query(constant.Page, "1")
+	pageSize := c.Query(constant.PageSize, "10")
+	pm := utils.InitPaginationMetadata(page, pageSize)
+
+	reviews, paginationMeta, err := h.SupplierService.GetReviews(pm, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
+	}
+
+	resp := map[string]interface{}{
+		"pagination_meta": paginationMeta,
+		"reviews":         reviews,
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", resp)
+}
+
+func (h *Handler) ReplyToReview(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	var req models.ReplyToReviewRequest
+
+	id := c.Params("id")
+	if id == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "id cannot be empty", nil)
+	}
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "invalid request body", nil)
+	}
+	if strings.TrimSpace(req.Reply) == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "reply cannot be empty", nil)
+	}
+	if len(req.Reply) > 2000 {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "reply cannot be longer than 2000 characters", nil)
+	}
+
+	review, err := h.SupplierService.ReplyToReview(id, req, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", review)
+}
@@ -57,7 +57,7 @@ func (h *Handler) Register(c *f.Ctx) error {
 	}
 
 	//trigger otp after registration
-	err = h.UtilitiesService.SendOtp(constant.RegisterOtp, email)
+	err = h.UtilitiesService.SendOtp(constant.RegisterOtp, email, nil)
 	if err != nil {
 		logger.Logger.Errorf("[Register]Send Otp error: %v", err)
 	}
@@ -0,0 +1,39 @@
+package supplier
+
+import (
+	"bambamload/constant"
+	"bambamload/models"
+	"bambamload/utils"
+	"net/http"
+
+	f "github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) GetWalletBalance(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
+	balance, err := h.UtilitiesService.GetWalletBalance(user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", balance)
+}
+
+func (h *Handler) GetWalletStatement(c *f.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
+	page := c.Query(constant.Page, "1")
+	pageSize := c.Query(constant.PageSize, "10")
+	pm := utils.InitPaginationMetadata(page, pageSize)
+
+	entries, paginationMeta, err := h.UtilitiesService.GetWalletStatement(pm, user)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
+	}
+
+	resp := map[string]interface{}{
+		"pagination_meta": paginationMeta,
+		"entries":         entries,
+	}
+	return utils.WriteResponse(c, http.StatusOK, true, "success", resp)
+}
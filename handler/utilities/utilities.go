@@ -5,7 +5,9 @@ import (
 	"bambamload/enum"
 	"bambamload/handler"
 	"bambamload/models"
+	utilitiesservice "bambamload/service/utilities"
 	"bambamload/utils"
+	"errors"
 	"net/http"
 
 	f "github.com/gofiber/fiber/v2"
@@ -74,7 +76,7 @@ func (h *Handler) ResendOtp(c *f.Ctx) error {
 		return utils.WriteResponse(c, http.StatusBadRequest, false, "email is required", nil)
 	}
 
-	err := h.UtilitiesService.SendOtp(req.Action, req.Email) //nolint:typecheck
+	err := h.UtilitiesService.SendOtp(req.Action, req.Email, nil) //nolint:typecheck
 	if err != nil {
 		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
 	}
@@ -94,7 +96,7 @@ func (h *Handler) ForgotPassword(c *f.Ctx) error {
 		return utils.WriteResponse(c, http.StatusBadRequest, false, "email is required", nil)
 	}
 
-	if err := h.UtilitiesService.SendOtp(constant.ForgotPassword, req.Email); err != nil { //nolint:typecheck
+	if err := h.UtilitiesService.SendOtp(constant.ForgotPassword, req.Email, nil); err != nil { //nolint:typecheck
 		return utils.WriteResponse(c, http.StatusInternalServerError, false, "unable to send otp, please try again", nil)
 	}
 
@@ -163,3 +165,29 @@ func (h *Handler) VerifyRegistrationOtp(c *f.Ctx) error {
 
 	return utils.WriteResponse(c, http.StatusOK, true, "successful", nil)
 }
+
+func (h *Handler) PaymentWebhook(c *f.Ctx) error {
+	err := h.UtilitiesService.HandlePaymentWebhook(c.Body(), c.Get(constant.PaymentSignatureHeader))
+	if err != nil {
+		if errors.Is(err, utilitiesservice.ErrInvalidWebhookSignature) {
+			return utils.WriteResponse(c, http.StatusUnauthorized, false, err.Error(), nil)
+		}
+		return utils.WriteResponse(c, http.StatusInternalServerError, false, err.Error(), nil)
+	}
+
+	return utils.WriteResponse(c, http.StatusOK, true, "successful", nil)
+}
+
+func (h *Handler) SimulateFakePayment(c *f.Ctx) error {
+	reference := c.Params("reference")
+	if reference == "" {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, "reference is required", nil)
+	}
+
+	err := h.UtilitiesService.SimulateFakePayment(reference, c.Query("status", constant.Success) == constant.Success)
+	if err != nil {
+		return utils.WriteResponse(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+
+	return utils.WriteResponse(c, http.StatusOK, true, "successful", nil)
+}
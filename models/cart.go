@@ -2,5 +2,72 @@ package models
 
 type Cart struct {
 	Model
-	BuyerID string `json:"buyer_id"`
+	BuyerID   string     `json:"buyer_id" gorm:"type:varchar(255);uniqueIndex"`
+	CartItems []CartItem `json:"cart_items" gorm:"foreignKey:CartID"`
+	SubTotal  int64      `json:"sub_total" gorm:"-"`
+}
+
+type CartItem struct {
+	Model
+	CartID     string  `json:"cart_id" gorm:"index;not null"`
+	ProductID  string  `json:"product_id" gorm:"index;not null"`
+	VariantID  string  `json:"variant_id,omitempty" gorm:"type:varchar(255)"`
+	SupplierID string  `json:"supplier_id" gorm:"type:varchar(255)"`
+	Quantity   int64   `json:"quantity" gorm:"type:int"`
+	UnitPrice  int64   `json:"unit_price" gorm:"type:int"` //price snapshot when the item was added
+	LineTotal  int64   `json:"line_total" gorm:"-"`
+	Product    Product `json:"product" gorm:"foreignKey:ProductID"`
+}
+
+// BuyerCart is the view of a cart shown to its buyer, with each product in its buyer view
+type BuyerCart struct {
+	ID        string          `json:"id"`
+	BuyerID   string          `json:"buyer_id"`
+	CartItems []BuyerCartItem `json:"cart_items"`
+	SubTotal  int64           `json:"sub_total"`
+}
+
+type BuyerCartItem struct {
+	ID         string       `json:"id"`
+	ProductID  string       `json:"product_id"`
+	VariantID  string       `json:"variant_id,omitempty"`
+	SupplierID string       `json:"supplier_id"`
+	Quantity   int64        `json:"quantity"`
+	UnitPrice  int64        `json:"unit_price"`
+	LineTotal  int64        `json:"line_total"`
+	Product    BuyerProduct `json:"product"`
+}
+
+// BuyerView projects a cart for its buyer
+func (c Cart) BuyerView() BuyerCart {
+	view := BuyerCart{
+		ID:        c.ID,
+		BuyerID:   c.BuyerID,
+		CartItems: make([]BuyerCartItem, 0, len(c.CartItems)),
+		SubTotal:  c.SubTotal,
+	}
+
+	for _, item := range c.CartItems {
+		view.CartItems = append(view.CartItems, BuyerCartItem{
+			ID:         item.ID,
+			ProductID:  item.ProductID,
+			VariantID:  item.VariantID,
+			SupplierID: item.SupplierID,
+			Quantity:   item.Quantity,
+			UnitPrice:  item.UnitPrice,
+			LineTotal:  item.LineTotal,
+			Product:    item.Product.BuyerView(),
+		})
+	}
+	return view
+}
+
+type AddCartItemRequest struct {
+	ProductID string `json:"product_id"`
+	VariantID string `json:"variant_id"` //required when the product has variants
+	Quantity  int64  `json:"quantity"`
+}
+
+type UpdateCartItemRequest struct {
+	Quantity int64 `json:"quantity"`
 }
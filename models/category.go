@@ -0,0 +1,46 @@
+package models
+
+// Category is a node in the product category tree. Top-level categories have no parent.
+// A product is filed under a category with no active children, and keeps the names of its top-level
+// category and that category in Category and Type.
+type Category struct {
+	Model
+	Name        string     `json:"name" gorm:"type:varchar(100)"`
+	ParentID    string     `json:"parent_id" gorm:"type:varchar(255);index"`
+	Description string     `json:"description" gorm:"type:varchar(500)"`
+	Status      string     `json:"status" gorm:"type:varchar(25);default:'active'"`
+	Children    []Category `json:"children,omitempty" gorm:"-"`
+}
+
+type CategoryRequest struct {
+	Name        string  `json:"name"`
+	ParentID    *string `json:"parent_id"` //an empty string moves the category to the top level
+	Description string  `json:"description"`
+	Status      string  `json:"status"`
+}
+
+// UnmappedCategory is a free-text category and type pair still used by products outside the category tree
+type UnmappedCategory struct {
+	Category            string `json:"category"`
+	Type                string `json:"type"`
+	Products            int64  `json:"products"`
+	SuggestedCategoryID string `json:"suggested_category_id,omitempty"` //the tree category the names already match, if any
+}
+
+// CategoryMapping files the products with a free-text category and type pair, matched case-insensitively,
+// under a category in the tree
+type CategoryMapping struct {
+	Category   string `json:"category"`
+	Type       string `json:"type"`
+	CategoryID string `json:"category_id"`
+}
+
+type MapCategoriesRequest struct {
+	Mappings []CategoryMapping `json:"mappings"`
+}
+
+type CategoryMappingResult struct {
+	CategoryMapping
+	Products int64  `json:"products"`
+	Error    string `json:"error,omitempty"`
+}
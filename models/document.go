@@ -0,0 +1,20 @@
+package models
+
+type OrderDocument struct {
+	Model
+	OrderID  string `json:"order_id" gorm:"type:varchar(255);uniqueIndex:idx_order_document_type"`
+	Type     string `json:"type" gorm:"type:varchar(20);uniqueIndex:idx_order_document_type"` //invoice or receipt
+	Number   string `json:"number" gorm:"type:varchar(100)"`
+	FileURL  string `json:"file_url" gorm:"type:text"`
+	FileType string `json:"file_type" gorm:"type:varchar(50)"`
+}
+
+// OrderDocumentData is everything printed on an invoice or receipt
+type OrderDocumentData struct {
+	Type             string
+	Number           string
+	Order            *Order
+	VatRate          float64
+	VatAmount        int64
+	CommissionAmount int64
+}
@@ -0,0 +1,25 @@
+package models
+
+// ProductImport tracks a supplier's bulk product upload as it is processed in the background
+type ProductImport struct {
+	Model
+	SupplierID    string `json:"supplier_id" gorm:"type:varchar(255);index"`
+	FileName      string `json:"file_name" gorm:"type:varchar(255)"`
+	FileURL       string `json:"file_url" gorm:"type:text"`
+	Status        string `json:"status" gorm:"type:varchar(25);default:'pending'"` //pending, processing, completed, failed
+	TotalRows     int64  `json:"total_rows" gorm:"type:int"`
+	CreatedCount  int64  `json:"created_count" gorm:"type:int"`
+	UpdatedCount  int64  `json:"updated_count" gorm:"type:int"`
+	FailedCount   int64  `json:"failed_count" gorm:"type:int"`
+	ReportURL     string `json:"report_url" gorm:"type:text"` //per-row results, including the reason each failed row was skipped
+	FailureReason string `json:"failure_reason" gorm:"type:varchar(255)"`
+}
+
+// ProductImportRow is the outcome of one row of an import, as written to its report
+type ProductImportRow struct {
+	Row       int
+	Status    string //created, updated or failed
+	ProductID string
+	Name      string
+	Error     string
+}
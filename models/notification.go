@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Notification is an in-app message to a user
+type Notification struct {
+	Model
+	UserID    string     `json:"user_id" gorm:"type:varchar(255);index"`
+	Type      string     `json:"type" gorm:"type:varchar(50)"`
+	Title     string     `json:"title" gorm:"type:varchar(255)"`
+	Message   string     `json:"message" gorm:"type:text"`
+	Reference string     `json:"reference" gorm:"type:varchar(255)"` //id of what the notification is about
+	ReadAt    *time.Time `json:"read_at"`
+}
+
+// StockAlert is a product, or a variant of one, whose stock has moved into or out of a low or out of stock state
+type StockAlert struct {
+	ProductID     string `json:"product_id"`
+	VariantID     string `json:"variant_id"`
+	SupplierID    string `json:"supplier_id"`
+	SupplierName  string `json:"supplier_name"`
+	SupplierEmail string `json:"supplier_email"`
+	ProductName   string `json:"product_name"`
+	VariantName   string `json:"variant_name"`
+	Stock         int64  `json:"stock"`
+	AlertLevel    int64  `json:"alert_level"`
+	PreviousState string `json:"previous_state"`
+	State         string `json:"state"` //low_stock, out_of_stock, or empty when stock is above the alert level
+}
+
+type MarkNotificationsReadRequest struct {
+	IDs []string `json:"ids"` //all unread notifications when empty
+}
@@ -1,6 +1,146 @@
 package models
 
+import "time"
+
 type Order struct {
 	Model
-	Status string `json:"status" gorm:"type:varchar(20)"`
+	Reference         string               `json:"reference" gorm:"type:varchar(100);uniqueIndex"`
+	CheckoutReference string               `json:"checkout_reference" gorm:"type:varchar(100);index"` //shared by all orders placed in one checkout
+	QuoteID           string               `json:"quote_id,omitempty" gorm:"type:varchar(255)"`       //set when the order came from an accepted quote
+	BuyerID           string               `json:"buyer_id" gorm:"type:varchar(255);index"`
+	SupplierID        string               `json:"supplier_id" gorm:"type:varchar(255);index"`
+	Status            string               `json:"status" gorm:"type:varchar(20);default:'pending'"`
+	PaymentTerms      string               `json:"payment_terms" gorm:"type:varchar(50)"` //prepayment or pay_on_delivery
+	PaymentStatus     string               `json:"payment_status" gorm:"type:varchar(20);default:'unpaid'"`
+	FulfilmentType    string               `json:"fulfilment_type" gorm:"type:varchar(50)"` //delivery or customer_pick_up
+	DeliveryAddress   string               `json:"delivery_address" gorm:"type:varchar(500)"`
+	Note              string               `json:"note" gorm:"type:varchar(500)"`
+	RejectReason      string               `json:"reject_reason" gorm:"type:varchar(500)"`
+	WaybillReference  string               `json:"waybill_reference" gorm:"type:varchar(100)"`
+	SubTotal          int64                `json:"sub_total" gorm:"type:bigint"`
+	TotalAmount       int64                `json:"total_amount" gorm:"type:bigint"`
+	CommissionRate    float32              `json:"commission_rate" gorm:"type:decimal(10,2)"` //supplier's rate when the order was placed
+	OrderItems        []OrderItem          `json:"order_items" gorm:"foreignKey:OrderID"`
+	StatusHistory     []OrderStatusHistory `json:"status_history" gorm:"foreignKey:OrderID"`
+	Shipments         []Shipment           `json:"shipments" gorm:"foreignKey:OrderID"`
+	Buyer             User                 `json:"buyer" gorm:"foreignKey:BuyerID"`
+	Supplier          User                 `json:"supplier" gorm:"foreignKey:SupplierID"`
+}
+
+type OrderItem struct {
+	Model
+	OrderID     string `json:"order_id" gorm:"index;not null"`
+	ProductID   string `json:"product_id" gorm:"index;not null"`
+	ProductName string `json:"product_name" gorm:"type:varchar(255)"`
+	VariantID   string `json:"variant_id,omitempty" gorm:"type:varchar(255)"`
+	VariantName string `json:"variant_name,omitempty" gorm:"type:varchar(255)"`
+	SKU         string `json:"sku,omitempty" gorm:"type:varchar(100)"`
+	Unit        string `json:"unit" gorm:"type:varchar(50)"`
+	Quantity    int64  `json:"quantity" gorm:"type:int"`
+	UnitPrice   int64  `json:"unit_price" gorm:"type:bigint"` //price snapshot at checkout
+	LineTotal   int64  `json:"line_total" gorm:"type:bigint"`
+
+	ShippedQuantity     int64 `json:"shipped_quantity" gorm:"type:int;default:0"`
+	BackorderedQuantity int64 `json:"backordered_quantity" gorm:"type:int;default:0"` //waiting on future stock
+	CancelledQuantity   int64 `json:"cancelled_quantity" gorm:"type:int;default:0"`
+}
+
+// OutstandingQuantity is the part of the line that has neither shipped nor been cancelled
+func (oi OrderItem) OutstandingQuantity() int64 {
+	return oi.Quantity - oi.ShippedQuantity - oi.CancelledQuantity
+}
+
+type OrderStatusHistory struct {
+	Model
+	OrderID       string `json:"order_id" gorm:"index;not null"`
+	FromStatus    string `json:"from_status" gorm:"type:varchar(20)"`
+	ToStatus      string `json:"to_status" gorm:"type:varchar(20)"`
+	ChangedBy     string `json:"changed_by" gorm:"type:varchar(255)"`
+	ChangedByName string `json:"changed_by_name" gorm:"type:varchar(100)"`
+	ChangedByRole string `json:"changed_by_role" gorm:"type:varchar(50)"`
+	Note          string `json:"note" gorm:"type:varchar(500)"`
+	IsOverride    bool   `json:"is_override" gorm:"default:false"` //admin forced the change outside the normal transitions
+}
+
+// OrderStatusChange describes a single move of an order from its current status to Status
+type OrderStatusChange struct {
+	Order     *Order
+	Status    string
+	ChangedBy *User
+	Note      string
+	Override  bool
+	Updates   map[string]interface{} //extra order columns written alongside the status
+}
+
+type CheckoutRequest struct {
+	PaymentTerms    string `json:"payment_terms"`
+	FulfilmentType  string `json:"fulfilment_type"`
+	DeliveryAddress string `json:"delivery_address"`
+	Note            string `json:"note"`
+}
+
+type OrderActionRequest struct {
+	Note string `json:"note"`
+}
+
+type CancelOrderRequest struct {
+	Reason       string `json:"reason"`
+	RefundMethod string `json:"refund_method"` //wallet or provider, defaults to wallet
+}
+
+type RejectOrderRequest struct {
+	Reason string `json:"reason"`
+}
+
+type DispatchOrderRequest struct {
+	WaybillReference string                `json:"waybill_reference"`
+	Note             string                `json:"note"`
+	Items            []ShipmentItemRequest `json:"items"` //empty ships everything outstanding
+}
+
+type ShipmentItemRequest struct {
+	OrderItemID string `json:"order_item_id"`
+	Quantity    int64  `json:"quantity"`
+}
+
+type ResolveUnfilledRequest struct {
+	Items []UnfilledItemRequest `json:"items"`
+	Note  string                `json:"note"`
+}
+
+type UnfilledItemRequest struct {
+	OrderItemID string `json:"order_item_id"`
+	Action      string `json:"action"` //backorder or cancel
+}
+
+type OverrideOrderStatusRequest struct {
+	Action string `json:"action"` //cancel or complete
+	Reason string `json:"reason"`
+}
+
+type ConfirmDeliveryRequest struct {
+	Code string `json:"code"`
+	Note string `json:"note"`
+}
+
+type OrderFilter struct {
+	BuyerID    string
+	SupplierID string
+	Status     string
+	From       time.Time
+	To         time.Time
+	MinAmount  int64
+	MaxAmount  int64
+}
+
+// StockReservation is stock held for a buyer while they check out
+type StockReservation struct {
+	ProductID string `json:"product_id"`
+	VariantID string `json:"variant_id,omitempty"`
+	Quantity  int64  `json:"quantity"`
+}
+
+type CheckoutReservation struct {
+	ExpiresAt time.Time          `json:"expires_at"`
+	Items     []StockReservation `json:"items"`
 }
@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+type Payment struct {
+	Model
+	Reference         string    `json:"reference" gorm:"type:varchar(100);uniqueIndex"`
+	CheckoutReference string    `json:"checkout_reference" gorm:"type:varchar(100);index"`
+	BuyerID           string    `json:"buyer_id" gorm:"type:varchar(255);index"`
+	Provider          string    `json:"provider" gorm:"type:varchar(50)"`
+	Amount            int64     `json:"amount" gorm:"type:bigint"` //kobo
+	Status            string    `json:"status" gorm:"type:varchar(20);default:'pending'"`
+	AuthorizationURL  string    `json:"authorization_url" gorm:"type:varchar(500)"`
+	PaidAt            time.Time `json:"paid_at" gorm:"type:timestamp"`
+}
+
+type InitializePaymentRequest struct {
+	Reference string
+	Email     string
+	Amount    int64 //kobo
+	Metadata  map[string]string
+}
+
+type PaymentInitialization struct {
+	Reference        string `json:"reference"`
+	AuthorizationURL string `json:"authorization_url"`
+	Amount           int64  `json:"amount"`
+}
+
+type PaymentVerification struct {
+	Reference string
+	Status    string
+	Amount    int64 //kobo
+	PaidAt    time.Time
+}
+
+type PaymentRefund struct {
+	Reference       string
+	RefundReference string
+	Status          string
+	Amount          int64 //kobo
+}
+
+type PaymentWebhookEvent struct {
+	Event     string `json:"event"`
+	Reference string `json:"reference"`
+	Status    string `json:"status"`
+	Amount    int64  `json:"amount"`
+}
+
+type CheckoutResponse struct {
+	Orders  []Order                `json:"orders"`
+	Payment *PaymentInitialization `json:"payment,omitempty"`
+}
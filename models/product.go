@@ -1,33 +1,56 @@
 package models
 
-import "time"
+import (
+	"bambamload/constant"
+	"time"
+)
 
 type Product struct {
 	Model
-	SupplierID            string          `json:"supplier_id" gorm:"type:varchar(255)"`
-	Name                  string          `json:"name" gorm:"type:varchar(255)"`
-	Category              string          `json:"category" gorm:"type:varchar(100)"`
-	Type                  string          `json:"type" gorm:"type:varchar(50)"`
-	Description           string          `json:"description" gorm:"type:text"`
-	BaseUnitPrice         int64           `json:"base_unit_price" gorm:"type:int"`
-	Unit                  string          `json:"unit" gorm:"type:varchar(50)"`
-	MinimumOrderQuantity  int64           `json:"minimum_order_quantity" gorm:"type:int"`
-	PaymentTerms          string          `json:"payment_terms" gorm:"type:varchar(50)"` //prepayment or pay_on_delivery
-	PaymentMethods        string          `json:"payment_methods" gorm:"type:text"`      // comma separated values
-	CurrentStockQuantity  int64           `json:"current_stock_quantity" gorm:"type:int"`
-	LowStockAlertLevel    int64           `json:"low_stock_alert_level" gorm:"type:int"`
-	FulfilmentType        string          `json:"fulfilment_type" gorm:"type:varchar(50)"` //delivery,customer_pick_up,both
-	EstimatedDeliveryTime string          `json:"estimated_delivery_time" gorm:"type:varchar(50)"`
-	Status                string          `json:"status" gorm:"type:varchar(25);default:'pending'"`
-	ApprovalStatus        string          `json:"approval_status" gorm:"type:varchar(25);default:'pending'"`
-	DateApproved          time.Time       `json:"date_approved" gorm:"type:date"`
-	DateRejected          time.Time       `json:"date_rejected" gorm:"type:date"`
-	ApprovedBy            string          `json:"approved_by" gorm:"type:varchar(100)"`
-	RejectedBy            string          `json:"rejected_by" gorm:"type:varchar(100)"`
-	Rating                int             `json:"rating" gorm:"type:int"`
-	RejectReason          string          `json:"reject_reason" gorm:"type:varchar(100)"`
-	ProductUploads        []ProductUpload `json:"product_uploads" gorm:"foreignKey:ProductID"`
-	Supplier              User            `json:"supplier" gorm:"foreignKey:SupplierID"`
+	SupplierID            string           `json:"supplier_id" gorm:"type:varchar(255)"`
+	Name                  string           `json:"name" gorm:"type:varchar(255)"`
+	CategoryID            string           `json:"category_id" gorm:"type:varchar(255);index"`
+	Category              string           `json:"category" gorm:"type:varchar(100)"`
+	Type                  string           `json:"type" gorm:"type:varchar(50)"`
+	Description           string           `json:"description" gorm:"type:text"`
+	BaseUnitPrice         int64            `json:"base_unit_price" gorm:"type:int"`
+	Unit                  string           `json:"unit" gorm:"type:varchar(50)"`
+	MinimumOrderQuantity  int64            `json:"minimum_order_quantity" gorm:"type:int"`
+	PaymentTerms          string           `json:"payment_terms" gorm:"type:varchar(50)"` //prepayment or pay_on_delivery
+	PaymentMethods        string           `json:"payment_methods" gorm:"type:text"`      // comma separated values
+	CurrentStockQuantity  int64            `json:"current_stock_quantity" gorm:"type:int"`
+	BackorderedQuantity   int64            `json:"backordered_quantity" gorm:"type:int;default:0"` //sold at checkout but not on hand, still owed to open orders
+	LowStockAlertLevel    int64            `json:"low_stock_alert_level" gorm:"type:int"`
+	StockAlertState       string           `json:"-" gorm:"type:varchar(25);default:''"`    //last stock alert sent, to announce each crossing once
+	FulfilmentType        string           `json:"fulfilment_type" gorm:"type:varchar(50)"` //delivery,customer_pick_up,both
+	EstimatedDeliveryTime string           `json:"estimated_delivery_time" gorm:"type:varchar(50)"`
+	Status                string           `json:"status" gorm:"type:varchar(25);default:'pending'"`
+	ApprovalStatus        string           `json:"approval_status" gorm:"type:varchar(25);default:'pending'"`
+	DateApproved          time.Time        `json:"date_approved" gorm:"type:date"`
+	DateRejected          time.Time        `json:"date_rejected" gorm:"type:date"`
+	ApprovedBy            string           `json:"approved_by" gorm:"type:varchar(100)"`
+	RejectedBy            string           `json:"rejected_by" gorm:"type:varchar(100)"`
+	Rating                float64          `json:"rating" gorm:"type:decimal(3,2);default:0"` //average of published reviews
+	ReviewCount           int64            `json:"review_count" gorm:"type:int;default:0"`
+	RatingTotal           int64            `json:"-" gorm:"type:int;default:0"` //sum of published review ratings
+	RejectReason          string           `json:"reject_reason" gorm:"type:varchar(100)"`
+	ProductUploads        []ProductUpload  `json:"product_uploads" gorm:"foreignKey:ProductID"`
+	PriceTiers            []PriceTier      `json:"price_tiers" gorm:"foreignKey:ProductID"`
+	Variants              []ProductVariant `json:"variants" gorm:"foreignKey:ProductID"`
+	UnitPrice             int64            `json:"unit_price,omitempty" gorm:"-"` //tier price for the quantity a buyer asked about
+	MinVariantPrice       int64            `json:"min_variant_price,omitempty" gorm:"-"`
+	MaxVariantPrice       int64            `json:"max_variant_price,omitempty" gorm:"-"`
+	ReservedQuantity      int64            `json:"-" gorm:"-"` //held in other buyers' checkouts
+	Supplier              User             `json:"supplier" gorm:"foreignKey:SupplierID"`
+}
+
+// PriceTier is the unit price for a band of order quantities. A MaxQuantity of 0 leaves the band open-ended.
+type PriceTier struct {
+	Model
+	ProductID   string `json:"product_id" gorm:"index;not null"`
+	MinQuantity int64  `json:"min_quantity" gorm:"type:int"`
+	MaxQuantity int64  `json:"max_quantity" gorm:"type:int"`
+	UnitPrice   int64  `json:"unit_price" gorm:"type:int"`
 }
 
 type ProductUpload struct {
@@ -52,9 +75,271 @@ type ProductStats struct {
 	Deactivated    int64 `json:"deactivated"`
 }
 
+type AddProductVariantsRequest struct {
+	Variants []ProductVariant `json:"variants"`
+}
+
+type EditProductVariantRequest struct {
+	Name                 string `json:"name"`
+	UnitPrice            int64  `json:"unit_price"`
+	CurrentStockQuantity *int64 `json:"current_stock_quantity"`
+	LowStockAlertLevel   *int64 `json:"low_stock_alert_level"`
+	Status               string `json:"status"`
+}
+
 type SupplierProductStats struct {
 	TotalProducts   int64 `json:"total_products"`
 	ActiveProducts  int64 `json:"active_products"`
 	LowStockItems   int64 `json:"low_stock_items"`
 	OutOfStockItems int64 `json:"out_of_stock_items"`
 }
+
+// ProductVariant is a size, grade or packaging of a product with its own price and stock.
+// The stock of a product with variants is kept as the sum of its variants' stock.
+type ProductVariant struct {
+	Model
+	ProductID            string `json:"product_id" gorm:"index;not null"`
+	SKU                  string `json:"sku" gorm:"type:varchar(100);uniqueIndex"`
+	Name                 string `json:"name" gorm:"type:varchar(255)"` //e.g. 50kg bag, grade A
+	UnitPrice            int64  `json:"unit_price" gorm:"type:int"`
+	CurrentStockQuantity int64  `json:"current_stock_quantity" gorm:"type:int"`
+	LowStockAlertLevel   int64  `json:"low_stock_alert_level" gorm:"type:int"`
+	StockAlertState      string `json:"-" gorm:"type:varchar(25);default:''"`
+	ReservedQuantity     int64  `json:"-" gorm:"-"`
+	Status               string `json:"status" gorm:"type:varchar(25);default:'active'"`
+}
+
+// HasVariants reports whether the product is sold by variant. Variants must be loaded.
+func (p Product) HasVariants() bool {
+	return len(p.Variants) > 0
+}
+
+// Variant returns the product's variant with the given id
+func (p Product) Variant(id string) (*ProductVariant, bool) {
+	for i := range p.Variants {
+		if p.Variants[i].ID == id {
+			return &p.Variants[i], true
+		}
+	}
+	return nil, false
+}
+
+// SetVariantPriceRange fills in the lowest and highest price across the product's variants
+func (p *Product) SetVariantPriceRange() {
+	for i, variant := range p.Variants {
+		if i == 0 || variant.UnitPrice < p.MinVariantPrice {
+			p.MinVariantPrice = variant.UnitPrice
+		}
+		if variant.UnitPrice > p.MaxVariantPrice {
+			p.MaxVariantPrice = variant.UnitPrice
+		}
+	}
+}
+
+// UnitPriceFor returns the unit price for an order quantity, falling back to BaseUnitPrice outside every tier.
+// PriceTiers must be loaded.
+func (p Product) UnitPriceFor(quantity int64) int64 {
+	for _, tier := range p.PriceTiers {
+		if quantity >= tier.MinQuantity && (tier.MaxQuantity == 0 || quantity <= tier.MaxQuantity) {
+			return tier.UnitPrice
+		}
+	}
+	return p.BaseUnitPrice
+}
+
+// PriceFor returns the unit price of a variant, or the tier price for the quantity when no variant is given
+func (p Product) PriceFor(variantID string, quantity int64) int64 {
+	if variant, ok := p.Variant(variantID); ok {
+		return variant.UnitPrice
+	}
+	return p.UnitPriceFor(quantity)
+}
+
+// AvailableQuantity is the product's stock less what is held in other buyers' checkouts. Backordered units
+// already left the stock figure at checkout, so they are not taken off again.
+func (p Product) AvailableQuantity() int64 {
+	return p.CurrentStockQuantity - p.ReservedQuantity
+}
+
+// AvailableQuantity is the variant's stock less what is held in other buyers' checkouts
+func (v ProductVariant) AvailableQuantity() int64 {
+	return v.CurrentStockQuantity - v.ReservedQuantity
+}
+
+// ShowAvailableStock replaces the stock figures of a product and its variants with what a buyer can still
+// order, for responses to buyers
+func (p *Product) ShowAvailableStock() {
+	p.CurrentStockQuantity = max(p.AvailableQuantity(), 0)
+	p.BackorderedQuantity, p.ReservedQuantity = 0, 0
+
+	for i := range p.Variants {
+		variant := &p.Variants[i]
+		variant.CurrentStockQuantity = max(variant.AvailableQuantity(), 0)
+		variant.ReservedQuantity = 0
+	}
+}
+
+// ProductExportRow is a product with its supplier's details, as written to a catalog export
+type ProductExportRow struct {
+	ID                    string
+	Name                  string
+	Category              string
+	Type                  string
+	Description           string
+	BaseUnitPrice         int64
+	Unit                  string
+	MinimumOrderQuantity  int64
+	PaymentTerms          string
+	PaymentMethods        string
+	CurrentStockQuantity  int64
+	LowStockAlertLevel    int64
+	FulfilmentType        string
+	EstimatedDeliveryTime string
+	Status                string
+	ApprovalStatus        string
+	CreatedAt             time.Time
+	SupplierID            string
+	SupplierBusinessName  string
+	SupplierName          string
+	SupplierEmail         string
+	SupplierPhoneNumber   string
+	SupplierStatus        string
+}
+
+type ProductFilter struct {
+	SupplierID              string
+	Status                  string
+	SearchText              string
+	Type                    string
+	MinPrice                int64
+	MaxPrice                int64
+	MaxMinimumOrderQuantity int64
+	FulfilmentType          string //delivery and customer_pick_up also match products offering both
+	PaymentTerms            string
+	SupplierState           string
+	SupplierRegion          string //matched against the regions a supplier serves
+	InStockOnly             bool
+	ListedOnly              bool   //approved, active products of approved, active suppliers who are not blocked
+	Sort                    string //newest, price_asc, price_desc, rating, popularity; search rank when empty and searching
+}
+
+// FacetCount is how many of the matching products have a value
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// ProductFacets breaks the products matching a search down by category, type, fulfilment type and price band
+type ProductFacets struct {
+	Categories      []FacetCount `json:"categories"`
+	Types           []FacetCount `json:"types"`
+	FulfilmentTypes []FacetCount `json:"fulfilment_types"`
+	PriceBands      []FacetCount `json:"price_bands"`
+}
+
+// BuyerProduct is the view of a listed product shown to buyers, leaving out its approval history and
+// stock internals
+type BuyerProduct struct {
+	ID                    string                `json:"id"`
+	CreatedAt             time.Time             `json:"createdAt"`
+	SupplierID            string                `json:"supplier_id"`
+	Name                  string                `json:"name"`
+	CategoryID            string                `json:"category_id"`
+	Category              string                `json:"category"`
+	Type                  string                `json:"type"`
+	Description           string                `json:"description"`
+	BaseUnitPrice         int64                 `json:"base_unit_price"`
+	UnitPrice             int64                 `json:"unit_price,omitempty"`
+	Unit                  string                `json:"unit"`
+	MinimumOrderQuantity  int64                 `json:"minimum_order_quantity"`
+	PaymentTerms          string                `json:"payment_terms"`
+	PaymentMethods        string                `json:"payment_methods"`
+	CurrentStockQuantity  int64                 `json:"current_stock_quantity"`
+	FulfilmentType        string                `json:"fulfilment_type"`
+	EstimatedDeliveryTime string                `json:"estimated_delivery_time"`
+	Rating                float64               `json:"rating"`
+	ReviewCount           int64                 `json:"review_count"`
+	MinVariantPrice       int64                 `json:"min_variant_price,omitempty"`
+	MaxVariantPrice       int64                 `json:"max_variant_price,omitempty"`
+	ProductUploads        []BuyerProductUpload  `json:"product_uploads"`
+	PriceTiers            []PriceTier           `json:"price_tiers"`
+	Variants              []BuyerProductVariant `json:"variants"`
+	Supplier              *BuyerProductSupplier `json:"supplier,omitempty"`
+}
+
+type BuyerProductUpload struct {
+	FileURL  string `json:"file_url"`
+	FileType string `json:"file_type"`
+}
+
+type BuyerProductVariant struct {
+	ID                   string `json:"id"`
+	SKU                  string `json:"sku"`
+	Name                 string `json:"name"`
+	UnitPrice            int64  `json:"unit_price"`
+	CurrentStockQuantity int64  `json:"current_stock_quantity"`
+}
+
+type BuyerProductSupplier struct {
+	ID            string `json:"id"`
+	BusinessName  string `json:"business_name"`
+	State         string `json:"state"`
+	RegionsServed string `json:"regions_served"`
+}
+
+// BuyerView projects a product for buyers. Inactive variants are left out; the supplier is included when loaded.
+func (p Product) BuyerView() BuyerProduct {
+	view := BuyerProduct{
+		ID:                    p.ID,
+		CreatedAt:             p.CreatedAt,
+		SupplierID:            p.SupplierID,
+		Name:                  p.Name,
+		CategoryID:            p.CategoryID,
+		Category:              p.Category,
+		Type:                  p.Type,
+		Description:           p.Description,
+		BaseUnitPrice:         p.BaseUnitPrice,
+		UnitPrice:             p.UnitPrice,
+		Unit:                  p.Unit,
+		MinimumOrderQuantity:  p.MinimumOrderQuantity,
+		PaymentTerms:          p.PaymentTerms,
+		PaymentMethods:        p.PaymentMethods,
+		CurrentStockQuantity:  p.CurrentStockQuantity,
+		FulfilmentType:        p.FulfilmentType,
+		EstimatedDeliveryTime: p.EstimatedDeliveryTime,
+		Rating:                p.Rating,
+		ReviewCount:           p.ReviewCount,
+		MinVariantPrice:       p.MinVariantPrice,
+		MaxVariantPrice:       p.MaxVariantPrice,
+		ProductUploads:        make([]BuyerProductUpload, 0, len(p.ProductUploads)),
+		PriceTiers:            p.PriceTiers,
+		Variants:              make([]BuyerProductVariant, 0, len(p.Variants)),
+	}
+
+	for _, upload := range p.ProductUploads {
+		view.ProductUploads = append(view.ProductUploads, BuyerProductUpload{FileURL: upload.FileURL, FileType: upload.FileType})
+	}
+
+	for _, variant := range p.Variants {
+		if variant.Status != constant.Active {
+			continue
+		}
+		view.Variants = append(view.Variants, BuyerProductVariant{
+			ID:                   variant.ID,
+			SKU:                  variant.SKU,
+			Name:                 variant.Name,
+			UnitPrice:            variant.UnitPrice,
+			CurrentStockQuantity: variant.CurrentStockQuantity,
+		})
+	}
+
+	if p.Supplier.ID != "" {
+		view.Supplier = &BuyerProductSupplier{
+			ID:            p.Supplier.ID,
+			BusinessName:  p.Supplier.BusinessName,
+			State:         p.Supplier.State,
+			RegionsServed: p.Supplier.RegionsServed,
+		}
+	}
+	return view
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+type Refund struct {
+	Model
+	Reference         string     `json:"reference" gorm:"type:varchar(100);uniqueIndex"`
+	OrderID           string     `json:"order_id" gorm:"type:varchar(255);index"`
+	BuyerID           string     `json:"buyer_id" gorm:"type:varchar(255);index"`
+	PaymentReference  string     `json:"payment_reference" gorm:"type:varchar(100)"`
+	Amount            int64      `json:"amount" gorm:"type:bigint"`                        //kobo
+	Method            string     `json:"method" gorm:"type:varchar(20)"`                   //wallet or provider
+	Status            string     `json:"status" gorm:"type:varchar(20);default:'pending'"` //pending, processing, success or failed
+	Reason            string     `json:"reason" gorm:"type:varchar(500)"`
+	ProviderReference string     `json:"provider_reference" gorm:"type:varchar(100)"`
+	FailureReason     string     `json:"failure_reason" gorm:"type:varchar(500)"`
+	ClaimedAt         *time.Time `json:"claimed_at,omitempty" gorm:"type:timestamp"` //when it was last sent to the provider
+	Order             *Order     `json:"order,omitempty" gorm:"foreignKey:OrderID"`
+}
+
+type RefundFilter struct {
+	Status string
+	Method string
+	From   time.Time
+	To     time.Time
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Review is a buyer's rating of a product they received on a completed order, one per product per order.
+// Only published reviews count towards the product's rating.
+type Review struct {
+	Model
+	ProductID        string     `json:"product_id" gorm:"type:varchar(255);uniqueIndex:idx_review_order_product;index"`
+	OrderID          string     `json:"order_id" gorm:"type:varchar(255);uniqueIndex:idx_review_order_product"`
+	BuyerID          string     `json:"buyer_id" gorm:"type:varchar(255);index"`
+	BuyerName        string     `json:"buyer_name" gorm:"type:varchar(100)"`
+	Rating           int        `json:"rating" gorm:"type:int"` //1 to 5 stars
+	Comment          string     `json:"comment" gorm:"type:varchar(2000)"`
+	Status           string     `json:"status" gorm:"type:varchar(20);default:'published'"` //published or hidden
+	Reply            string     `json:"reply,omitempty" gorm:"type:varchar(2000)"`          //the supplier's one reply
+	RepliedAt        *time.Time `json:"replied_at,omitempty" gorm:"type:timestamp"`
+	ModeratedBy      string     `json:"moderated_by,omitempty" gorm:"type:varchar(100)"`
+	ModerationReason string     `json:"moderation_reason,omitempty" gorm:"type:varchar(500)"`
+}
+
+type CreateReviewRequest struct {
+	OrderID string `json:"order_id"`
+	Rating  int    `json:"rating"`
+	Comment string `json:"comment"`
+}
+
+type ReplyToReviewRequest struct {
+	Reply string `json:"reply"`
+}
+
+type ModerateReviewRequest struct {
+	Action string `json:"action"` //hide or publish
+	Reason string `json:"reason"`
+}
+
+type ReviewFilter struct {
+	ProductID  string
+	SupplierID string
+	Status     string
+}
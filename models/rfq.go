@@ -0,0 +1,102 @@
+package models
+
+import (
+	"bambamload/constant"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Rfq is a buyer's request for quotes on a product, or on anything in a category
+type Rfq struct {
+	Model
+	Reference       string    `json:"reference" gorm:"type:varchar(100);uniqueIndex"`
+	BuyerID         string    `json:"buyer_id" gorm:"type:varchar(255);index"`
+	ProductID       string    `json:"product_id,omitempty" gorm:"type:varchar(255);index"`
+	Category        string    `json:"category,omitempty" gorm:"type:varchar(100);index"`
+	Quantity        int64     `json:"quantity" gorm:"type:int"`
+	PaymentTerms    string    `json:"payment_terms" gorm:"type:varchar(50)"`
+	FulfilmentType  string    `json:"fulfilment_type" gorm:"type:varchar(50)"`
+	DeliveryAddress string    `json:"delivery_address" gorm:"type:varchar(500)"`
+	RequiredBy      time.Time `json:"required_by" gorm:"type:date"`
+	Note            string    `json:"note" gorm:"type:varchar(500)"`
+	Status          string    `json:"status" gorm:"type:varchar(20);default:'open'"` //open, accepted or cancelled
+	Buyer           User      `json:"buyer" gorm:"foreignKey:BuyerID"`
+	Product         *Product  `json:"product,omitempty" gorm:"foreignKey:ProductID"`
+	Quotes          []Quote   `json:"quotes,omitempty" gorm:"foreignKey:RfqID"`
+}
+
+// Quote is a supplier's offer on an RFQ. UnitPrice, Quantity and ExpiresAt hold the latest offer from either side,
+// and AwaitingRole is the side that has to accept, counter or reject it.
+type Quote struct {
+	Model
+	RfqID         string       `json:"rfq_id" gorm:"index;not null"`
+	SupplierID    string       `json:"supplier_id" gorm:"type:varchar(255);index"`
+	ProductID     string       `json:"product_id" gorm:"type:varchar(255)"`
+	VariantID     string       `json:"variant_id,omitempty" gorm:"type:varchar(255)"`
+	UnitPrice     int64        `json:"unit_price" gorm:"type:bigint"`
+	Quantity      int64        `json:"quantity" gorm:"type:int"`
+	DeliveryTerms string       `json:"delivery_terms" gorm:"type:varchar(500)"`
+	ExpiresAt     time.Time    `json:"expires_at" gorm:"type:timestamp"`
+	Status        string       `json:"status" gorm:"type:varchar(20);default:'pending'"` //pending, accepted, rejected or expired
+	AwaitingRole  string       `json:"awaiting_role" gorm:"type:varchar(20)"`
+	OrderID       string       `json:"order_id,omitempty" gorm:"type:varchar(255)"`
+	Rfq           *Rfq         `json:"rfq,omitempty" gorm:"foreignKey:RfqID"`
+	Product       Product      `json:"product" gorm:"foreignKey:ProductID"`
+	Supplier      User         `json:"supplier" gorm:"foreignKey:SupplierID"`
+	Offers        []QuoteOffer `json:"offers,omitempty" gorm:"foreignKey:QuoteID"`
+}
+
+// AfterFind reports a pending quote past its expiry as expired
+func (q *Quote) AfterFind(tx *gorm.DB) error {
+	if q.Status == constant.Pending && !q.ExpiresAt.IsZero() && time.Now().After(q.ExpiresAt) {
+		q.Status = constant.Expired
+	}
+	return nil
+}
+
+// QuoteOffer is one offer or counter-offer in the negotiation on a quote
+type QuoteOffer struct {
+	Model
+	QuoteID       string    `json:"quote_id" gorm:"index;not null"`
+	OfferedBy     string    `json:"offered_by" gorm:"type:varchar(255)"`
+	OfferedByRole string    `json:"offered_by_role" gorm:"type:varchar(20)"`
+	UnitPrice     int64     `json:"unit_price" gorm:"type:bigint"`
+	Quantity      int64     `json:"quantity" gorm:"type:int"`
+	ExpiresAt     time.Time `json:"expires_at" gorm:"type:timestamp"`
+	Note          string    `json:"note" gorm:"type:varchar(500)"`
+}
+
+type RfqFilter struct {
+	BuyerID    string
+	SupplierID string //open RFQs a supplier can quote on
+	Status     string
+}
+
+type CreateRfqRequest struct {
+	ProductID       string `json:"product_id"`
+	Category        string `json:"category"`
+	Quantity        int64  `json:"quantity"`
+	PaymentTerms    string `json:"payment_terms"`
+	FulfilmentType  string `json:"fulfilment_type"`
+	DeliveryAddress string `json:"delivery_address"`
+	RequiredBy      string `json:"required_by"` //YYYY-MM-DD
+	Note            string `json:"note"`
+}
+
+type SubmitQuoteRequest struct {
+	ProductID     string `json:"product_id"`
+	VariantID     string `json:"variant_id"` //required when the product has variants
+	UnitPrice     int64  `json:"unit_price"`
+	Quantity      int64  `json:"quantity"`
+	DeliveryTerms string `json:"delivery_terms"`
+	ExpiresAt     string `json:"expires_at"` //RFC3339
+	Note          string `json:"note"`
+}
+
+type CounterOfferRequest struct {
+	UnitPrice int64  `json:"unit_price"`
+	Quantity  int64  `json:"quantity"`
+	ExpiresAt string `json:"expires_at"` //RFC3339, keeps the current expiry when empty
+	Note      string `json:"note"`
+}
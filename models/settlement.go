@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+type Settlement struct {
+	Model
+	OrderID          string  `json:"order_id" gorm:"type:varchar(255);uniqueIndex"`
+	OrderReference   string  `json:"order_reference" gorm:"type:varchar(100)"`
+	SupplierID       string  `json:"supplier_id" gorm:"type:varchar(255);index"`
+	PaymentTerms     string  `json:"payment_terms" gorm:"type:varchar(50)"` //on pay_on_delivery the supplier holds the cash and owes the commission
+	GrossAmount      int64   `json:"gross_amount" gorm:"type:bigint"`
+	CommissionRate   float32 `json:"commission_rate" gorm:"type:decimal(10,2)"`
+	CommissionAmount int64   `json:"commission_amount" gorm:"type:bigint"`
+	NetAmount        int64   `json:"net_amount" gorm:"type:bigint"` //amount payable to the supplier
+	Status           string  `json:"status" gorm:"type:varchar(20);default:'pending'"`
+	Supplier         User    `json:"supplier" gorm:"foreignKey:SupplierID"`
+}
+
+type SettlementFilter struct {
+	SupplierID string
+	Status     string
+	From       time.Time
+	To         time.Time
+}
+
+type SettlementSummary struct {
+	TotalOrders      int64 `json:"total_orders"`
+	GrossAmount      int64 `json:"gross_amount"`
+	CommissionAmount int64 `json:"commission_amount"`
+	NetAmount        int64 `json:"net_amount"`
+}
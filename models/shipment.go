@@ -0,0 +1,19 @@
+package models
+
+type Shipment struct {
+	Model
+	OrderID          string         `json:"order_id" gorm:"index;not null"`
+	Reference        string         `json:"reference" gorm:"type:varchar(100);uniqueIndex"`
+	WaybillReference string         `json:"waybill_reference" gorm:"type:varchar(100)"`
+	Note             string         `json:"note" gorm:"type:varchar(500)"`
+	ShipmentItems    []ShipmentItem `json:"shipment_items" gorm:"foreignKey:ShipmentID"`
+}
+
+type ShipmentItem struct {
+	Model
+	ShipmentID    string `json:"shipment_id" gorm:"index;not null"`
+	OrderItemID   string `json:"order_item_id" gorm:"index;not null"`
+	ProductID     string `json:"product_id" gorm:"type:varchar(255)"`
+	Quantity      int64  `json:"quantity" gorm:"type:int"`
+	FromBackorder int64  `json:"from_backorder" gorm:"type:int;default:0"` //part of Quantity that filled a backorder
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+var ErrImmutableStockMovement = errors.New("stock movements cannot be modified")
+
+// StockMovement is an entry in a product's stock ledger. Stock is the running sum of movements, kept per
+// variant for products sold by variant and per product otherwise.
+type StockMovement struct {
+	Model
+	ProductID    string `json:"product_id" gorm:"index;not null"`
+	VariantID    string `json:"variant_id" gorm:"type:varchar(255);index"`
+	Type         string `json:"type" gorm:"type:varchar(25)"` //opening_balance, receipt, sale, adjustment, return, damage
+	Quantity     int64  `json:"quantity" gorm:"type:int"`     //positive adds to stock, negative takes from it
+	BalanceAfter int64  `json:"balance_after" gorm:"type:int"`
+	Reference    string `json:"reference" gorm:"type:varchar(100);index"` //order or shipment reference for sales and returns
+	Reason       string `json:"reason" gorm:"type:varchar(255)"`
+	CreatedBy    string `json:"created_by" gorm:"type:varchar(255)"`
+}
+
+func (s *StockMovement) BeforeUpdate(tx *gorm.DB) error {
+	return ErrImmutableStockMovement
+}
+
+func (s *StockMovement) BeforeDelete(tx *gorm.DB) error {
+	return ErrImmutableStockMovement
+}
+
+// StockCount sets stock to a counted level, recording the difference as an adjustment
+type StockCount struct {
+	ProductID string
+	VariantID string
+	Quantity  int64
+	Reason    string
+	CreatedBy string
+}
+
+// StockAdjustmentRequest is a supplier's stock movement. Quantity is always positive except for an
+// adjustment, where its sign says whether stock goes up or down.
+type StockAdjustmentRequest struct {
+	VariantID string `json:"variant_id"`
+	Type      string `json:"type"` //receipt, adjustment, return, damage
+	Quantity  int64  `json:"quantity"`
+	Reason    string `json:"reason"`
+}
+
+// EditProductRequest takes the stock level as a pointer so it can be set to zero
+type EditProductRequest struct {
+	Product
+	CurrentStockQuantity *int64 `json:"current_stock_quantity"`
+}
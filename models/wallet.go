@@ -0,0 +1,60 @@
+package models
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+var ErrImmutableLedgerEntry = errors.New("ledger entries cannot be modified")
+
+type Wallet struct {
+	Model
+	OwnerID       string `json:"owner_id" gorm:"type:varchar(255);uniqueIndex"` //user id, or the name of a system wallet
+	Type          string `json:"type" gorm:"type:varchar(20)"`                  //user or system
+	Currency      string `json:"currency" gorm:"type:varchar(10)"`
+	AllowNegative bool   `json:"-" gorm:"default:false"`
+}
+
+// WalletTransaction groups the balanced debit and credit entries of one movement of money
+type WalletTransaction struct {
+	Model
+	Reference     string        `json:"reference" gorm:"type:varchar(100);uniqueIndex"`
+	Description   string        `json:"description" gorm:"type:varchar(255)"`
+	Amount        int64         `json:"amount" gorm:"type:bigint"` //kobo
+	LedgerEntries []LedgerEntry `json:"ledger_entries,omitempty" gorm:"foreignKey:TransactionID"`
+}
+
+type LedgerEntry struct {
+	Model
+	TransactionID string `json:"transaction_id" gorm:"index;not null"`
+	WalletID      string `json:"wallet_id" gorm:"index;not null"`
+	EntryType     string `json:"entry_type" gorm:"type:varchar(10)"` //credit or debit
+	Amount        int64  `json:"amount" gorm:"type:bigint"`          //kobo
+	BalanceAfter  int64  `json:"balance_after" gorm:"type:bigint"`   //kobo
+	Reference     string `json:"reference" gorm:"type:varchar(100);index"`
+	Description   string `json:"description" gorm:"type:varchar(255)"`
+}
+
+func (l *LedgerEntry) BeforeUpdate(tx *gorm.DB) error {
+	return ErrImmutableLedgerEntry
+}
+
+func (l *LedgerEntry) BeforeDelete(tx *gorm.DB) error {
+	return ErrImmutableLedgerEntry
+}
+
+// WalletTransfer moves Amount kobo from the wallet owned by DebitOwnerID to the wallet owned by CreditOwnerID
+type WalletTransfer struct {
+	DebitOwnerID  string
+	CreditOwnerID string
+	Amount        int64
+	Reference     string
+	Description   string
+}
+
+type WalletBalance struct {
+	Wallet   *Wallet `json:"wallet"`
+	Balance  int64   `json:"balance"`
+	Currency string  `json:"currency"`
+}
@@ -35,9 +35,37 @@ func AdminRoutes(app *f.App, h *adminhandler.Handler) {
 	admin.Get("/product/:id", h.GetProduct)
 	admin.Get("/products", h.GetProducts)
 	admin.Get("/products/cards", h.GetAdminProductCards)
+	admin.Get("/products/export", h.ExportProducts)
 
 	admin.Post("/products/approve_or_reject", h.ApproveOrRejectSupplierProduct)
 
+	//reviews
+	admin.Get("/reviews", h.GetReviews)
+	admin.Post("/review/:id/moderate", h.ModerateReview)
+
+	//categories
+	admin.Post("/category", h.CreateCategory)
+	admin.Put("/category/:id", h.UpdateCategory)
+	admin.Delete("/category/:id", h.DeleteCategory)
+	admin.Get("/categories", h.GetCategories)
+	admin.Get("/categories/unmapped", h.GetUnmappedCategories)
+	admin.Post("/categories/map", h.MapCategories)
+
+	//orders
+	admin.Get("/order/:id", h.GetOrder)
+	admin.Get("/orders", h.GetOrders)
+	admin.Post("/order/:id/override", h.OverrideOrderStatus)
+	admin.Get("/order/:id/documents/:type", h.GetOrderDocument)
+
+	//settlements
+	admin.Get("/settlements", h.GetSettlements)
+
+	//refunds
+	admin.Get("/refund/:id", h.GetRefund)
+	admin.Get("/refunds", h.GetRefunds)
+	admin.Post("/refund/:id/retry", h.RetryRefund)
+	admin.Post("/refund/:id/reset", h.ResetRefund)
+
 	admin.Post("/logout", h.LogoutAdmin)
 
 }
@@ -17,6 +17,44 @@ func BuyerRoutes(app *f.App, h *buyerHandler.Handler) {
 	buyer.Get("/me", h.Me)
 	buyer.Get("/product/:id", h.GetProduct)
 	buyer.Get("/products", h.GetProducts)
+	buyer.Get("/categories", h.GetCategories)
+	buyer.Post("/product/:id/reviews", h.CreateReview)
+	buyer.Get("/product/:id/reviews", h.GetProductReviews)
+
+	//cart
+	buyer.Get("/cart", h.GetCart)
+	buyer.Delete("/cart", h.ClearCart)
+	buyer.Post("/cart/items", h.AddCartItem)
+	buyer.Put("/cart/items/:id", h.UpdateCartItem)
+	buyer.Delete("/cart/items/:id", h.RemoveCartItem)
+
+	//orders
+	buyer.Post("/checkout/reserve", h.ReserveCheckout)
+	buyer.Delete("/checkout/reserve", h.ReleaseCheckout)
+	buyer.Post("/checkout", h.Checkout)
+	buyer.Get("/order/:id", h.GetOrder)
+	buyer.Get("/orders", h.GetOrders)
+	buyer.Post("/order/:id/confirm_receipt", h.ConfirmOrderReceipt)
+	buyer.Post("/order/:id/cancel", h.CancelOrder)
+	buyer.Post("/order/:id/delivery_code", h.ResendDeliveryCode)
+	buyer.Get("/order/:id/documents/:type", h.GetOrderDocument)
+
+	//rfqs
+	buyer.Post("/rfq", h.CreateRfq)
+	buyer.Get("/rfq/:id", h.GetRfq)
+	buyer.Get("/rfqs", h.GetRfqs)
+	buyer.Post("/rfq/:id/cancel", h.CancelRfq)
+	buyer.Post("/quote/:id/counter", h.CounterQuote)
+	buyer.Post("/quote/:id/accept", h.AcceptQuote)
+	buyer.Post("/quote/:id/reject", h.RejectQuote)
+
+	//payments
+	buyer.Post("/checkout/:reference/pay", h.InitializeCheckoutPayment)
+	buyer.Get("/payment/:reference/verify", h.VerifyPayment)
+
+	//wallet
+	buyer.Get("/wallet", h.GetWalletBalance)
+	buyer.Get("/wallet/statement", h.GetWalletStatement)
 
 	buyer.Post("/logout", h.LogoutBuyer)
 }
@@ -23,8 +23,48 @@ func SupplierRoutes(app *f.App, h *supplierHandler.Handler) {
 	supplier.Get("/product/:id", h.GetProduct)
 	supplier.Get("/products", h.GetProducts)
 	supplier.Get("/products/stats", h.GetSupplierProductStats)
+	supplier.Get("/categories", h.GetCategories)
+	supplier.Post("/products/import", h.ImportProducts)
+	supplier.Get("/products/imports", h.GetProductImports)
+	supplier.Get("/products/import/:id", h.GetProductImport)
 
 	supplier.Post("/product/images/:id", h.UploadProductImages)
+	supplier.Post("/product/:id/variants", h.AddProductVariants)
+	supplier.Put("/product/:id/variant/:variant_id", h.EditProductVariant)
+	supplier.Post("/product/:id/stock_adjustments", h.AdjustStock)
+	supplier.Get("/product/:id/stock_movements", h.GetStockMovements)
+
+	//reviews
+	supplier.Get("/reviews", h.GetReviews)
+	supplier.Post("/review/:id/reply", h.ReplyToReview)
+
+	//orders
+	supplier.Get("/order/:id", h.GetOrder)
+	supplier.Get("/orders", h.GetOrders)
+	supplier.Post("/order/:id/accept", h.AcceptOrder)
+	supplier.Post("/order/:id/reject", h.RejectOrder)
+	supplier.Post("/order/:id/cancel", h.CancelOrder)
+	supplier.Post("/order/:id/dispatch", h.DispatchOrder)
+	supplier.Post("/order/:id/resolve_unfilled", h.ResolveUnfilledItems)
+	supplier.Post("/order/:id/confirm_delivery", h.ConfirmDelivery)
+	supplier.Get("/order/:id/documents/:type", h.GetOrderDocument)
+
+	//rfqs
+	supplier.Get("/rfq/:id", h.GetRfq)
+	supplier.Get("/rfqs", h.GetRfqs)
+	supplier.Post("/rfq/:id/quote", h.SubmitQuote)
+	supplier.Get("/quotes", h.GetQuotes)
+	supplier.Post("/quote/:id/counter", h.CounterQuote)
+	supplier.Post("/quote/:id/accept", h.AcceptQuote)
+	supplier.Post("/quote/:id/reject", h.RejectQuote)
+
+	//wallet
+	supplier.Get("/wallet", h.GetWalletBalance)
+	supplier.Get("/wallet/statement", h.GetWalletStatement)
+
+	//notifications
+	supplier.Get("/notifications", h.GetNotifications)
+	supplier.Post("/notifications/read", h.MarkNotificationsRead)
 
 	supplier.Post("/logout", h.LogoutSupplier)
 }
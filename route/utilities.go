@@ -1,7 +1,9 @@
 package route
 
 import (
+	"bambamload/constant"
 	utilitiesHandler "bambamload/handler/utilities"
+	"os"
 
 	f "github.com/gofiber/fiber/v2"
 )
@@ -18,4 +20,11 @@ func UtilitiesRoutes(app *f.App, h *utilitiesHandler.Handler) {
 
 	app.Get("/utilities/user_by_reference", h.GetUserRegistrationDetailsByReference)
 
+	app.Post("/webhooks/payment", h.PaymentWebhook)
+
+	//completing fake payments by hand is only for local development
+	if os.Getenv(constant.AppEnv) == constant.Development {
+		app.Get("/utilities/payments/fake/:reference/pay", h.SimulateFakePayment)
+	}
+
 }
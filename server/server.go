@@ -13,11 +13,13 @@ import (
 	"bambamload/service/admin"
 	"bambamload/service/buyer"
 	"bambamload/service/email"
+	paymentservice "bambamload/service/paymentService"
 	"bambamload/service/postgresrepository"
 	"bambamload/service/redisService"
 	"bambamload/service/supplier"
 	uploadservice "bambamload/service/uploadService"
 	"bambamload/service/utilities"
+	"bambamload/worker"
 	"fmt"
 	"os"
 	"os/signal"
@@ -39,16 +41,19 @@ func Start() {
 	emailService := email.NewEmailService()
 	//smsService := smsservice.NewSMSService()
 	uploadService := uploadservice.NewUploadService()
+	paymentProvider := paymentservice.NewPaymentProvider()
 	adminService := admin.NewServiceAdmin(rs, pg, *emailService)
 	supplierService := supplier.NewServiceSupplier(rs, pg, *emailService, uploadService)
-	buyerService := buyer.NewServiceBuyer(rs, pg, *emailService, uploadService)
-	utilitiesService := utilities.NewServiceUtilities(rs, pg, *emailService, uploadService)
+	buyerService := buyer.NewServiceBuyer(rs, pg, *emailService, uploadService, paymentProvider)
+	utilitiesService := utilities.NewServiceUtilities(rs, pg, *emailService, uploadService, paymentProvider)
 	apiHandler := handler.NewHandler(rs, *pg, *emailService, uploadService, adminService, supplierService, buyerService, utilitiesService)
 	adminHandler := adminhandler.NewAdminHandler(apiHandler)
 	supplierHandler := supplierhandler.NewSupplierHandler(apiHandler)
 	buyerHandler := buyerhandler.NewBuyerHandler(apiHandler)
 	utilitiesHandler := utilitieshandler.NewUtilitiesHandler(apiHandler)
 
+	worker.NewWorker(rs, supplierService).Start()
+
 	app := f.New()
 
 	// CORS
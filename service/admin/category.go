@@ -0,0 +1,209 @@
+package admin
+
+import (
+	"bambamload/constant"
+	"bambamload/logger"
+	"bambamload/models"
+	"bambamload/service/postgresrepository"
+	"errors"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+func (sa *ServiceAdmin) CreateCategory(req models.CategoryRequest) (*models.Category, error) {
+	category := &models.Category{
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Status:      constant.Active,
+	}
+	if req.ParentID != nil {
+		category.ParentID = *req.ParentID
+	}
+	if req.Status != "" {
+		category.Status = req.Status
+	}
+
+	if category.ParentID != "" {
+		if _, err := sa.getCategory(category.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := sa.checkCategoryName(category.ParentID, category.Name, ""); err != nil {
+		return nil, err
+	}
+
+	if err := sa.PostgresRepository.CreateCategory(category); err != nil {
+		return nil, errors.New("unable to create category, please try again later")
+	}
+	return category, nil
+}
+
+// UpdateCategory renames, describes, moves or changes the status of a category. Products filed under it or
+// below it take on the new names.
+func (sa *ServiceAdmin) UpdateCategory(id string, req models.CategoryRequest) (*models.Category, error) {
+	category, err := sa.getCategory(id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]interface{})
+	name, parentID := category.Name, category.ParentID
+
+	if req.Name = strings.TrimSpace(req.Name); req.Name != "" {
+		name = req.Name
+		updates["name"] = name
+	}
+	if req.Description != "" {
+		updates["description"] = req.Description
+	}
+	if req.Status != "" {
+		updates["status"] = req.Status
+	}
+
+	if req.ParentID != nil && *req.ParentID != category.ParentID {
+		parentID = *req.ParentID
+		updates["parent_id"] = parentID
+
+		if parentID != "" {
+			if _, err = sa.getCategory(parentID); err != nil {
+				return nil, err
+			}
+
+			subtree, err := sa.PostgresRepository.CategorySubtree(id)
+			if err != nil {
+				logger.Logger.Errorf("[UpdateCategory]Failed to get subtree of category %s: %v", id, err)
+				return nil, errors.New("unable to update category, please try again later")
+			}
+			if slices.Contains(subtree, parentID) {
+				return nil, errors.New("a category cannot be moved under itself or one of its subcategories")
+			}
+		}
+	}
+
+	if len(updates) == 0 {
+		return category, nil
+	}
+
+	if _, renamed := updates["name"]; renamed || parentID != category.ParentID {
+		if err = sa.checkCategoryName(parentID, name, id); err != nil {
+			return nil, err
+		}
+	}
+
+	rootName := name
+	if parentID != "" {
+		path, err := sa.PostgresRepository.CategoryPath(parentID)
+		if err != nil {
+			logger.Logger.Errorf("[UpdateCategory]Failed to get path of category %s: %v", parentID, err)
+			return nil, errors.New("unable to update category, please try again later")
+		}
+		rootName = path[0].Name
+	}
+
+	if err = sa.PostgresRepository.UpdateCategory(id, updates, rootName); err != nil {
+		return nil, errors.New("unable to update category, please try again later")
+	}
+	return sa.getCategory(id)
+}
+
+// DeleteCategory removes a category that has no subcategories and no products. Categories in use are
+// deactivated instead.
+func (sa *ServiceAdmin) DeleteCategory(id string) error {
+	if _, err := sa.getCategory(id); err != nil {
+		return err
+	}
+
+	children, products, err := sa.PostgresRepository.CategoryUsage(id)
+	if err != nil {
+		logger.Logger.Errorf("[DeleteCategory]Failed to get usage of category %s: %v", id, err)
+		return errors.New("unable to delete category, please try again later")
+	}
+	if children > 0 {
+		return errors.New("category has subcategories, please delete or move them first")
+	}
+	if products > 0 {
+		return errors.New("category has products filed under it, please deactivate it instead")
+	}
+
+	if err = sa.PostgresRepository.DeleteCategory(id); err != nil {
+		return errors.New("unable to delete category, please try again later")
+	}
+	return nil
+}
+
+// GetUnmappedCategories lists the free-text categories of products outside the category tree, suggesting the
+// tree category their names already match
+func (sa *ServiceAdmin) GetUnmappedCategories() ([]models.UnmappedCategory, error) {
+	unmapped, err := sa.PostgresRepository.GetUnmappedCategories()
+	if err != nil {
+		return nil, errors.New("unable to get unmapped categories, please try again later")
+	}
+
+	for i := range unmapped {
+		path, err := sa.PostgresRepository.ResolveCategory("", unmapped[i].Category, unmapped[i].Type)
+		if err == nil {
+			unmapped[i].SuggestedCategoryID = path[len(path)-1].ID
+		}
+	}
+	return unmapped, nil
+}
+
+// MapCategories files the products of each free-text category and type pair under a category in the tree.
+// Each mapping is applied on its own, so one bad mapping does not hold back the rest.
+func (sa *ServiceAdmin) MapCategories(req models.MapCategoriesRequest) []models.CategoryMappingResult {
+	results := make([]models.CategoryMappingResult, 0, len(req.Mappings))
+
+	for _, mapping := range req.Mappings {
+		result := models.CategoryMappingResult{CategoryMapping: mapping}
+		if mapping.CategoryID == "" {
+			result.Error = "category_id cannot be empty"
+			results = append(results, result)
+			continue
+		}
+
+		path, err := sa.PostgresRepository.ResolveCategory(mapping.CategoryID, "", "")
+		switch {
+		case errors.Is(err, postgresrepository.ErrCategoryNotFound), errors.Is(err, postgresrepository.ErrCategoryInactive),
+			errors.Is(err, postgresrepository.ErrCategoryNotLeaf):
+			result.Error = err.Error()
+		case err != nil:
+			logger.Logger.Errorf("[MapCategories]Failed to resolve category %s: %v", mapping.CategoryID, err)
+			result.Error = "unable to map category, please try again later"
+		default:
+			result.Products, err = sa.PostgresRepository.MapProductCategory(mapping, path[0].Name, path[len(path)-1].Name)
+			if err != nil {
+				result.Error = "unable to map category, please try again later"
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func (sa *ServiceAdmin) getCategory(id string) (*models.Category, error) {
+	category, err := sa.PostgresRepository.GetCategory(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("category does not exist")
+		}
+		logger.Logger.Errorf("[getCategory]Failed to get category %s: %v", id, err)
+		return nil, errors.New("unable to get category, please try again later")
+	}
+	return category, nil
+}
+
+// checkCategoryName keeps category names unique among their siblings, ignoring case
+func (sa *ServiceAdmin) checkCategoryName(parentID, name, excludeID string) error {
+	taken, err := sa.PostgresRepository.CategoryNameTaken(parentID, name, excludeID)
+	if err != nil {
+		logger.Logger.Errorf("[checkCategoryName]Failed to check category name %s: %v", name, err)
+		return errors.New("unable to save category, please try again later")
+	}
+	if taken {
+		return errors.New("a category with this name already exists here")
+	}
+	return nil
+}
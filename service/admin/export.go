@@ -0,0 +1,43 @@
+package admin
+
+import (
+	"bambamload/logger"
+	"bambamload/models"
+	"bambamload/utils"
+	"io"
+)
+
+var productExportHeader = []interface{}{
+	"id", "name", "category", "type", "description", "base_unit_price", "unit", "minimum_order_quantity",
+	"payment_terms", "payment_methods", "current_stock_quantity", "low_stock_alert_level", "fulfilment_type",
+	"estimated_delivery_time", "status", "approval_status", "created_at", "supplier_id", "supplier_business_name",
+	"supplier_name", "supplier_email", "supplier_phone_number", "supplier_status",
+}
+
+// ExportProducts writes the products matching the product list filters, with their suppliers, to w as a CSV
+// or XLSX file. Rows are written as they are read from the database.
+func (sa *ServiceAdmin) ExportProducts(w io.Writer, format string, filter models.ProductFilter) error {
+	sheet, err := utils.NewSpreadsheetWriter(w, format)
+	if err != nil {
+		return err
+	}
+
+	if err = sheet.WriteRow(productExportHeader); err != nil {
+		return err
+	}
+
+	err = sa.PostgresRepository.StreamProductExport(filter, func(row models.ProductExportRow) error {
+		return sheet.WriteRow([]interface{}{
+			row.ID, row.Name, row.Category, row.Type, row.Description, row.BaseUnitPrice, row.Unit, row.MinimumOrderQuantity,
+			row.PaymentTerms, row.PaymentMethods, row.CurrentStockQuantity, row.LowStockAlertLevel, row.FulfilmentType,
+			row.EstimatedDeliveryTime, row.Status, row.ApprovalStatus, row.CreatedAt.Format("2006-01-02 15:04:05"), row.SupplierID,
+			row.SupplierBusinessName, row.SupplierName, row.SupplierEmail, row.SupplierPhoneNumber, row.SupplierStatus,
+		})
+	})
+	if err != nil {
+		logger.Logger.Errorf("[ExportProducts]Failed to export products: %v", err)
+		_ = sheet.Close()
+		return err
+	}
+	return sheet.Close()
+}
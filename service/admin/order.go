@@ -0,0 +1,86 @@
+package admin
+
+import (
+	"bambamload/constant"
+	"bambamload/logger"
+	"bambamload/models"
+	"bambamload/service/postgresrepository"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func (sa *ServiceAdmin) GetOrders(pm *models.PaginationMetadata, filter models.OrderFilter) ([]models.Order, *models.PaginationMetadata, error) {
+	orders, paginationMetaData, err := sa.PostgresRepository.GetOrders(pm, filter)
+	if err != nil {
+		logger.Logger.Errorf("[GetOrders]Failed to get orders: %v", err)
+		return nil, pm, errors.New("unable to get orders")
+	}
+	return orders, paginationMetaData, nil
+}
+
+func (sa *ServiceAdmin) GetOrder(id string) (*models.Order, error) {
+	order, err := sa.PostgresRepository.GetOrder(id, constant.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("order does not exist")
+		}
+		return nil, errors.New("unable to get order")
+	}
+	return order, nil
+}
+
+// OverrideOrderStatus force-cancels or force-completes an order outside the normal transitions.
+// The change is flagged as an override in the order status history together with the admin and reason.
+// Cancelling a paid order refunds it back through the payment provider; the refund is returned for processing.
+func (sa *ServiceAdmin) OverrideOrderStatus(id string, req models.OverrideOrderStatusRequest, user *models.User) (*models.Refund, error) {
+	order, err := sa.GetOrder(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		status string
+		refund *models.Refund
+	)
+
+	change := models.OrderStatusChange{
+		Order:     order,
+		ChangedBy: user,
+		Note:      req.Reason,
+		Override:  true,
+	}
+
+	switch req.Action {
+	case constant.Cancel:
+		if order.Status != constant.Pending && order.Status != constant.Confirmed && order.Status != constant.InTransit && order.Status != constant.Delivered {
+			return nil, fmt.Errorf("a %s order cannot be cancelled", order.Status)
+		}
+		status = constant.Cancelled
+
+		refund, err = sa.PostgresRepository.CancelOrder(change, constant.Provider)
+
+	case constant.Complete:
+		if order.Status != constant.Confirmed && order.Status != constant.InTransit && order.Status != constant.Delivered {
+			return nil, fmt.Errorf("a %s order cannot be completed", order.Status)
+		}
+		status = constant.Completed
+
+		change.Status = status
+		err = sa.PostgresRepository.ChangeOrderStatus(change, nil)
+
+	default:
+		return nil, errors.New("action can only be cancel/complete")
+	}
+	if err != nil {
+		if errors.Is(err, postgresrepository.ErrOrderStatusChanged) {
+			return nil, err
+		}
+		logger.Logger.Errorf("[OverrideOrderStatus]Failed to override order %s: %v", order.ID, err)
+		return nil, errors.New("unable to override order status, please try again later")
+	}
+
+	logger.Logger.Infof("[OverrideOrderStatus]admin %s moved order %s to %s: %s", user.ID, order.Reference, status, req.Reason)
+	return refund, nil
+}
@@ -0,0 +1,47 @@
+package admin
+
+import (
+	"bambamload/logger"
+	"bambamload/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func (sa *ServiceAdmin) GetRefunds(pm *models.PaginationMetadata, filter models.RefundFilter) ([]models.Refund, *models.PaginationMetadata, error) {
+	refunds, paginationMetaData, err := sa.PostgresRepository.GetRefunds(pm, filter)
+	if err != nil {
+		logger.Logger.Errorf("[GetRefunds]Failed to get refunds: %v", err)
+		return nil, pm, errors.New("unable to get refunds")
+	}
+	return refunds, paginationMetaData, nil
+}
+
+func (sa *ServiceAdmin) GetRefund(id string) (*models.Refund, error) {
+	refund, err := sa.PostgresRepository.GetRefund(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("refund does not exist")
+		}
+		return nil, errors.New("unable to get refund")
+	}
+	return refund, nil
+}
+
+// refundClaimTimeout is how long a refund may wait on the provider before an admin can reset it
+const refundClaimTimeout = 30 * time.Minute
+
+// ResetRefund fails a provider refund stuck in processing, so it can be retried once the admin has checked with
+// the payment provider that it was not paid out.
+func (sa *ServiceAdmin) ResetRefund(id string) (*models.Refund, error) {
+	reset, err := sa.PostgresRepository.ResetStaleRefund(id, time.Now().UTC().Add(-refundClaimTimeout),
+		"reset by admin after the payment provider did not answer")
+	if err != nil {
+		return nil, errors.New("unable to reset refund, please try again later")
+	}
+	if !reset {
+		return nil, errors.New("only refunds processing for more than 30 minutes can be reset")
+	}
+	return sa.GetRefund(id)
+}
@@ -0,0 +1,44 @@
+package admin
+
+import (
+	"bambamload/constant"
+	"bambamload/logger"
+	"bambamload/models"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+func (sa *ServiceAdmin) GetReviews(pm *models.PaginationMetadata, filter models.ReviewFilter) ([]models.Review, *models.PaginationMetadata, error) {
+	reviews, paginationMetaData, err := sa.PostgresRepository.GetReviews(pm, filter)
+	if err != nil {
+		return nil, pm, errors.New("unable to get reviews, please try again later")
+	}
+	return reviews, paginationMetaData, nil
+}
+
+// ModerateReview hides a review from buyers, taking it out of its product's rating, or publishes it again
+func (sa *ServiceAdmin) ModerateReview(id string, req models.ModerateReviewRequest, user *models.User) (*models.Review, error) {
+	review, err := sa.PostgresRepository.GetReview(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("review does not exist")
+		}
+		logger.Logger.Errorf("[ModerateReview]Failed to get review %s: %v", id, err)
+		return nil, errors.New("unable to moderate review, please try again later")
+	}
+
+	status := constant.Published
+	if req.Action == constant.Hide {
+		status = constant.Hidden
+	}
+
+	changed, err := sa.PostgresRepository.SetReviewStatus(review, status, user.Name, req.Reason)
+	if err != nil {
+		return nil, errors.New("unable to moderate review, please try again later")
+	}
+	if !changed {
+		return nil, errors.New("review is already " + status)
+	}
+	return sa.PostgresRepository.GetReview(review.ID)
+}
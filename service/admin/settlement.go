@@ -0,0 +1,27 @@
+package admin
+
+import (
+	"bambamload/logger"
+	"bambamload/models"
+	"errors"
+)
+
+func (sa *ServiceAdmin) GetSettlements(pm *models.PaginationMetadata, filter models.SettlementFilter) (any, error) {
+	settlements, paginationMetaData, err := sa.PostgresRepository.GetSettlements(pm, filter)
+	if err != nil {
+		logger.Logger.Errorf("[GetSettlements]Failed to get settlements: %v", err)
+		return nil, errors.New("unable to get settlements")
+	}
+
+	summary, err := sa.PostgresRepository.GetSettlementSummary(filter)
+	if err != nil {
+		logger.Logger.Errorf("[GetSettlements]Failed to get settlement summary: %v", err)
+		return nil, errors.New("unable to get settlements")
+	}
+
+	return map[string]interface{}{
+		"pagination_meta": paginationMetaData,
+		"summary":         summary,
+		"settlements":     settlements,
+	}, nil
+}
@@ -6,6 +6,7 @@ import (
 	"bambamload/logger"
 	"bambamload/models"
 	"bambamload/service/email"
+	"bambamload/service/paymentService"
 	"bambamload/service/postgresrepository"
 	"bambamload/service/redisService"
 	"bambamload/service/uploadService"
@@ -19,14 +20,16 @@ type ServiceBuyer struct {
 	PostgresRepository *postgresrepository.PostgresRepository
 	EmailService       email.Email
 	UploadService      *uploadService.UploadService
+	PaymentProvider    paymentService.PaymentProvider
 }
 
-func NewServiceBuyer(redisService redisService.RedisService, postgresRepository *postgresrepository.PostgresRepository, emailService email.Email, uploadService *uploadService.UploadService) *ServiceBuyer {
+func NewServiceBuyer(redisService redisService.RedisService, postgresRepository *postgresrepository.PostgresRepository, emailService email.Email, uploadService *uploadService.UploadService, paymentProvider paymentService.PaymentProvider) *ServiceBuyer {
 	return &ServiceBuyer{
 		RedisService:       redisService,
 		PostgresRepository: postgresRepository,
 		EmailService:       emailService,
 		UploadService:      uploadService,
+		PaymentProvider:    paymentProvider,
 	}
 }
 
@@ -0,0 +1,185 @@
+package buyer
+
+import (
+	"bambamload/constant"
+	"bambamload/logger"
+	"bambamload/models"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func (sb *ServiceBuyer) GetCart(user *models.User) (*models.BuyerCart, error) {
+	cart, err := sb.PostgresRepository.GetOrCreateCart(user.ID)
+	if err != nil {
+		logger.Logger.Errorf("[GetCart]Failed to get cart: %v", err)
+		return nil, errors.New("unable to get cart, please try again later")
+	}
+
+	//prices follow the product's current tier for the quantity in the cart
+	for i := range cart.CartItems {
+		item := &cart.CartItems[i]
+		item.UnitPrice = item.Product.PriceFor(item.VariantID, item.Quantity)
+		item.LineTotal = item.UnitPrice * item.Quantity
+		cart.SubTotal += item.LineTotal
+	}
+
+	view := cart.BuyerView()
+	return &view, nil
+}
+
+func (sb *ServiceBuyer) AddCartItem(req models.AddCartItemRequest, user *models.User) (*models.BuyerCart, error) {
+	product, err := sb.PostgresRepository.GetListedProduct(req.ProductID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("product does not exist")
+		}
+		logger.Logger.Errorf("[AddCartItem]Failed to get product: %v", err)
+		return nil, errors.New("unable to add item to cart, please try again later")
+	}
+
+	cart, err := sb.PostgresRepository.GetOrCreateCart(user.ID)
+	if err != nil {
+		logger.Logger.Errorf("[AddCartItem]Failed to get cart: %v", err)
+		return nil, errors.New("unable to add item to cart, please try again later")
+	}
+
+	//adding a product already in the cart tops up the existing line
+	var existing *models.CartItem
+	for i := range cart.CartItems {
+		if cart.CartItems[i].ProductID == product.ID && cart.CartItems[i].VariantID == req.VariantID {
+			existing = &cart.CartItems[i]
+			break
+		}
+	}
+
+	quantity := req.Quantity
+	if existing != nil {
+		quantity += existing.Quantity
+	}
+
+	sb.applyReservations(product, user)
+	if err = validateCartQuantity(product, req.VariantID, quantity); err != nil {
+		return nil, err
+	}
+
+	if existing != nil {
+		err = sb.PostgresRepository.UpdateCartItem(existing.ID, map[string]interface{}{
+			"quantity":   quantity,
+			"unit_price": product.PriceFor(req.VariantID, quantity),
+		})
+	} else {
+		err = sb.PostgresRepository.CreateCartItem(&models.CartItem{
+			CartID:     cart.ID,
+			ProductID:  product.ID,
+			VariantID:  req.VariantID,
+			SupplierID: product.SupplierID,
+			Quantity:   quantity,
+			UnitPrice:  product.PriceFor(req.VariantID, quantity),
+		})
+	}
+	if err != nil {
+		logger.Logger.Errorf("[AddCartItem]Failed to save cart item: %v", err)
+		return nil, errors.New("unable to add item to cart, please try again later")
+	}
+
+	sb.releaseReservations(user)
+	return sb.GetCart(user)
+}
+
+func (sb *ServiceBuyer) UpdateCartItem(id string, req models.UpdateCartItemRequest, user *models.User) (*models.BuyerCart, error) {
+	cart, err := sb.PostgresRepository.GetOrCreateCart(user.ID)
+	if err != nil {
+		logger.Logger.Errorf("[UpdateCartItem]Failed to get cart: %v", err)
+		return nil, errors.New("unable to update cart item, please try again later")
+	}
+
+	item, err := sb.PostgresRepository.GetCartItem(id, cart.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("cart item does not exist")
+		}
+		return nil, errors.New("unable to update cart item, please try again later")
+	}
+
+	sb.applyReservations(&item.Product, user)
+	if err = validateCartQuantity(&item.Product, item.VariantID, req.Quantity); err != nil {
+		return nil, err
+	}
+
+	err = sb.PostgresRepository.UpdateCartItem(item.ID, map[string]interface{}{
+		"quantity":   req.Quantity,
+		"unit_price": item.Product.PriceFor(item.VariantID, req.Quantity),
+	})
+	if err != nil {
+		return nil, errors.New("unable to update cart item, please try again later")
+	}
+
+	sb.releaseReservations(user)
+	return sb.GetCart(user)
+}
+
+func (sb *ServiceBuyer) RemoveCartItem(id string, user *models.User) (*models.BuyerCart, error) {
+	cart, err := sb.PostgresRepository.GetOrCreateCart(user.ID)
+	if err != nil {
+		logger.Logger.Errorf("[RemoveCartItem]Failed to get cart: %v", err)
+		return nil, errors.New("unable to remove cart item, please try again later")
+	}
+
+	if err = sb.PostgresRepository.DeleteCartItem(id, cart.ID); err != nil {
+		return nil, errors.New("unable to remove cart item, please try again later")
+	}
+
+	sb.releaseReservations(user)
+	return sb.GetCart(user)
+}
+
+func (sb *ServiceBuyer) ClearCart(user *models.User) error {
+	cart, err := sb.PostgresRepository.GetOrCreateCart(user.ID)
+	if err != nil {
+		logger.Logger.Errorf("[ClearCart]Failed to get cart: %v", err)
+		return errors.New("unable to clear cart, please try again later")
+	}
+
+	if err = sb.PostgresRepository.ClearCart(cart.ID); err != nil {
+		return errors.New("unable to clear cart, please try again later")
+	}
+
+	sb.releaseReservations(user)
+	return nil
+}
+
+// validateCartQuantity checks that a product, or the chosen variant of it, can be bought and that the
+// quantity respects its order limits
+func validateCartQuantity(product *models.Product, variantID string, quantity int64) error {
+	if product.ApprovalStatus != constant.Approved || product.Status != constant.Active {
+		return errors.New("product is not available for purchase")
+	}
+
+	if product.HasVariants() {
+		variant, ok := product.Variant(variantID)
+		if !ok {
+			return errors.New("please choose a valid variant of this product")
+		}
+		if variant.Status != constant.Active {
+			return errors.New("this variant is not available for purchase")
+		}
+		if quantity > variant.AvailableQuantity() {
+			return fmt.Errorf("only %d units of this variant are in stock", max(variant.AvailableQuantity(), 0))
+		}
+	} else if variantID != "" {
+		return errors.New("this product has no variants")
+	}
+
+	if quantity <= 0 {
+		return errors.New("quantity must be greater than zero")
+	}
+	if quantity < product.MinimumOrderQuantity {
+		return fmt.Errorf("minimum order quantity for this product is %d", product.MinimumOrderQuantity)
+	}
+	if quantity > product.AvailableQuantity() {
+		return fmt.Errorf("only %d units of this product are in stock", max(product.AvailableQuantity(), 0))
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package buyer
+
+import (
+	"bambamload/constant"
+	"bambamload/logger"
+	"bambamload/models"
+	"bambamload/service/postgresrepository"
+	"bambamload/utils"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Checkout turns the buyer's cart into one order per supplier
+func (sb *ServiceBuyer) Checkout(req models.CheckoutRequest, user *models.User) (*models.CheckoutResponse, error) {
+	cart, err := sb.PostgresRepository.GetOrCreateCart(user.ID)
+	if err != nil {
+		logger.Logger.Errorf("[Checkout]Failed to get cart: %v", err)
+		return nil, errors.New("unable to checkout, please try again later")
+	}
+	if len(cart.CartItems) == 0 {
+		return nil, errors.New("cart is empty")
+	}
+
+	//the cart is held, or its earlier hold renewed, so the stock cannot go to another buyer mid checkout
+	if _, err = sb.reserveCart(cart, user); err != nil {
+		return nil, err
+	}
+
+	checkoutRef := utils.GenerateReference("CHK")
+	ordersBySupplier := make(map[string]*models.Order)
+	var supplierIDs []string
+
+	for _, item := range cart.CartItems {
+		product := item.Product
+		if err = validateCartQuantity(&product, item.VariantID, item.Quantity); err != nil {
+			return nil, fmt.Errorf("%s: %v", product.Name, err)
+		}
+		if product.PaymentTerms != "" && product.PaymentTerms != req.PaymentTerms {
+			return nil, fmt.Errorf("%s can only be paid for by %s", product.Name, strings.ReplaceAll(product.PaymentTerms, "_", " "))
+		}
+		if product.FulfilmentType != "" && product.FulfilmentType != constant.Both && product.FulfilmentType != req.FulfilmentType {
+			return nil, fmt.Errorf("%s is only available for %s", product.Name, strings.ReplaceAll(product.FulfilmentType, "_", " "))
+		}
+
+		order, ok := ordersBySupplier[product.SupplierID]
+		if !ok {
+			order = &models.Order{
+				Reference:         utils.GenerateReference("ORD"),
+				CheckoutReference: checkoutRef,
+				BuyerID:           user.ID,
+				SupplierID:        product.SupplierID,
+				Status:            constant.Pending,
+				PaymentTerms:      req.PaymentTerms,
+				PaymentStatus:     constant.Unpaid,
+				FulfilmentType:    req.FulfilmentType,
+				DeliveryAddress:   req.DeliveryAddress,
+				Note:              req.Note,
+			}
+			ordersBySupplier[product.SupplierID] = order
+			supplierIDs = append(supplierIDs, product.SupplierID)
+		}
+
+		unitPrice := product.PriceFor(item.VariantID, item.Quantity)
+		lineTotal := unitPrice * item.Quantity
+		orderItem := models.OrderItem{
+			ProductID:   product.ID,
+			ProductName: product.Name,
+			Unit:        product.Unit,
+			Quantity:    item.Quantity,
+			UnitPrice:   unitPrice,
+			LineTotal:   lineTotal,
+		}
+		if variant, ok := product.Variant(item.VariantID); ok {
+			orderItem.VariantID, orderItem.VariantName, orderItem.SKU = variant.ID, variant.Name, variant.SKU
+		}
+		order.OrderItems = append(order.OrderItems, orderItem)
+		order.SubTotal += lineTotal
+		order.TotalAmount += lineTotal
+	}
+
+	//each order keeps its supplier's commission rate at the time it was placed
+	commissionRates, err := sb.PostgresRepository.GetCommissionRates(supplierIDs)
+	if err != nil {
+		logger.Logger.Errorf("[Checkout]Failed to get commission rates: %v", err)
+		return nil, errors.New("unable to checkout, please try again later")
+	}
+
+	orders := make([]models.Order, 0, len(supplierIDs))
+	for _, supplierID := range supplierIDs {
+		order := ordersBySupplier[supplierID]
+		order.CommissionRate = commissionRates[supplierID]
+		orders = append(orders, *order)
+	}
+
+	err = sb.PostgresRepository.PlaceOrders(cart.ID, orders)
+	if err != nil {
+		if errors.Is(err, postgresrepository.ErrInsufficientStock) || errors.Is(err, postgresrepository.ErrProductNotAvailable) {
+			return nil, err
+		}
+		logger.Logger.Errorf("[Checkout]Failed to place orders: %v", err)
+		return nil, errors.New("unable to checkout, please try again later")
+	}
+
+	//the held stock has now been sold
+	sb.releaseReservations(user)
+
+	resp := &models.CheckoutResponse{Orders: orders}
+
+	//prepaid orders can still be paid for later through the checkout payment endpoint if this fails
+	if req.PaymentTerms == constant.Prepayment {
+		resp.Payment, err = sb.InitializeCheckoutPayment(checkoutRef, user)
+		if err != nil {
+			logger.Logger.Errorf("[Checkout]Failed to initialize payment for %s: %v", checkoutRef, err)
+		}
+	}
+
+	return resp, nil
+}
+
+func (sb *ServiceBuyer) GetOrders(pm *models.PaginationMetadata, status string, user *models.User) ([]models.Order, *models.PaginationMetadata, error) {
+	orders, paginationMetaData, err := sb.PostgresRepository.GetOrders(pm, models.OrderFilter{
+		BuyerID: user.ID,
+		Status:  status,
+	})
+	if err != nil {
+		logger.Logger.Errorf("[GetOrders]Failed to get buyer orders: %v", err)
+		return nil, pm, errors.New("unable to get orders")
+	}
+	return orders, paginationMetaData, nil
+}
+
+func (sb *ServiceBuyer) GetOrder(id string, user *models.User) (*models.Order, error) {
+	order, err := sb.PostgresRepository.GetOrder(id, constant.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("order does not exist")
+		}
+		return nil, errors.New("unable to get order")
+	}
+	if order.BuyerID != user.ID {
+		return nil, errors.New("order does not exist")
+	}
+	return order, nil
+}
+
+// ConfirmOrderReceipt lets the buyer mark a delivered order as completed
+func (sb *ServiceBuyer) ConfirmOrderReceipt(id, note string, user *models.User) error {
+	order, err := sb.GetOrder(id, user)
+	if err != nil {
+		return err
+	}
+
+	if err = utils.ValidateOrderTransition(order.Status, constant.Completed); err != nil {
+		return err
+	}
+
+	err = sb.PostgresRepository.ChangeOrderStatus(models.OrderStatusChange{
+		Order:     order,
+		Status:    constant.Completed,
+		ChangedBy: user,
+		Note:      note,
+	}, nil)
+	if err != nil {
+		if errors.Is(err, postgresrepository.ErrOrderStatusChanged) {
+			return err
+		}
+		return errors.New("unable to confirm order receipt, please try again later")
+	}
+	return nil
+}
+
+// CancelOrder cancels an order that has not been dispatched. A paid order is refunded to the buyer's wallet,
+// or back through the payment provider when asked; provider refunds are returned for processing.
+func (sb *ServiceBuyer) CancelOrder(id string, req models.CancelOrderRequest, user *models.User) (*models.Refund, error) {
+	order, err := sb.GetOrder(id, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.Status != constant.Pending && order.Status != constant.Confirmed {
+		return nil, errors.New("only orders that have not been dispatched can be cancelled")
+	}
+
+	refundMethod := req.RefundMethod
+	if refundMethod == "" {
+		refundMethod = constant.Wallet
+	}
+	if refundMethod != constant.Wallet && refundMethod != constant.Provider {
+		return nil, errors.New("refund method can only be wallet/provider")
+	}
+
+	refund, err := sb.PostgresRepository.CancelOrder(models.OrderStatusChange{
+		Order:     order,
+		ChangedBy: user,
+		Note:      req.Reason,
+	}, refundMethod)
+	if err != nil {
+		if errors.Is(err, postgresrepository.ErrOrderStatusChanged) {
+			return nil, err
+		}
+		return nil, errors.New("unable to cancel order, please try again later")
+	}
+
+	body := utils.BuildOrderUpdateEmail(order.Supplier.BusinessName, order.Reference, "An order has been cancelled",
+		fmt.Sprintf("%s has cancelled their order.\n\nReason: %s", user.Name, req.Reason))
+	if err = sb.EmailService.Send(order.Supplier.Email, fmt.Sprintf("Order cancelled - %s", order.Reference), body); err != nil {
+		logger.Logger.Errorf("[CancelOrder]Failed to send email for order %s: %v", order.Reference, err)
+	}
+	return refund, nil
+}
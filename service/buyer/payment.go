@@ -0,0 +1,121 @@
+package buyer
+
+import (
+	"bambamload/constant"
+	"bambamload/logger"
+	"bambamload/models"
+	"bambamload/service/paymentService"
+	"bambamload/service/postgresrepository"
+	"bambamload/utils"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// InitializeCheckoutPayment starts a provider payment for the prepaid orders of a checkout that are still unpaid
+func (sb *ServiceBuyer) InitializeCheckoutPayment(checkoutReference string, user *models.User) (*models.PaymentInitialization, error) {
+	if sb.PaymentProvider == nil {
+		return nil, paymentService.ErrPaymentsUnavailable
+	}
+
+	orders, err := sb.PostgresRepository.GetOrdersByCheckoutReference(checkoutReference)
+	if err != nil {
+		return nil, errors.New("unable to initialize payment, please try again later")
+	}
+
+	var amount int64
+	for _, order := range orders {
+		if order.BuyerID != user.ID {
+			return nil, errors.New("checkout does not exist")
+		}
+		if order.PaymentTerms != constant.Prepayment || order.PaymentStatus == constant.Paid {
+			continue
+		}
+		if order.Status == constant.Cancelled || order.Status == constant.Rejected {
+			continue
+		}
+		amount += order.TotalAmount
+	}
+	if amount <= 0 {
+		return nil, errors.New("there is nothing to pay for on this checkout")
+	}
+
+	payment := &models.Payment{
+		Reference:         utils.GenerateReference("PAY"),
+		CheckoutReference: checkoutReference,
+		BuyerID:           user.ID,
+		Provider:          sb.PaymentProvider.Name(),
+		Amount:            utils.ToKobo(amount),
+		Status:            constant.Pending,
+	}
+	//a payment already started for the same amount is handed back rather than charging the checkout twice
+	open, created, err := sb.PostgresRepository.OpenCheckoutPayment(payment)
+	if err != nil {
+		if errors.Is(err, postgresrepository.ErrCheckoutAlreadyPaid) {
+			return nil, err
+		}
+		return nil, errors.New("unable to initialize payment, please try again later")
+	}
+	if !created {
+		return &models.PaymentInitialization{
+			Reference:        open.Reference,
+			AuthorizationURL: open.AuthorizationURL,
+			Amount:           open.Amount,
+		}, nil
+	}
+
+	initialization, err := sb.PaymentProvider.Initialize(models.InitializePaymentRequest{
+		Reference: payment.Reference,
+		Email:     user.Email,
+		Amount:    payment.Amount,
+		Metadata:  map[string]string{"checkout_reference": checkoutReference},
+	})
+	if err != nil {
+		logger.Logger.Errorf("[InitializeCheckoutPayment]provider initialize error: %v", err)
+		_ = sb.PostgresRepository.UpdatePayment(payment.Reference, map[string]interface{}{"status": constant.Failed})
+		return nil, errors.New("unable to initialize payment, please try again later")
+	}
+
+	err = sb.PostgresRepository.UpdatePayment(payment.Reference, map[string]interface{}{"authorization_url": initialization.AuthorizationURL})
+	if err != nil {
+		logger.Logger.Errorf("[InitializeCheckoutPayment]Failed to save authorization url: %v", err)
+	}
+
+	return initialization, nil
+}
+
+// VerifyPayment asks the provider for the outcome of a payment and applies it
+func (sb *ServiceBuyer) VerifyPayment(reference string, user *models.User) (*models.Payment, error) {
+	if sb.PaymentProvider == nil {
+		return nil, paymentService.ErrPaymentsUnavailable
+	}
+
+	payment, err := sb.PostgresRepository.GetPayment(reference)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("payment does not exist")
+		}
+		return nil, errors.New("unable to verify payment, please try again later")
+	}
+	if payment.BuyerID != user.ID {
+		return nil, errors.New("payment does not exist")
+	}
+	if payment.Status == constant.Success {
+		return payment, nil
+	}
+
+	verification, err := sb.PaymentProvider.Verify(reference)
+	if err != nil {
+		logger.Logger.Errorf("[VerifyPayment]provider verify error: %v", err)
+		return nil, errors.New("unable to verify payment, please try again later")
+	}
+
+	payment, err = sb.PostgresRepository.ApplyPaymentVerification(verification)
+	if err != nil {
+		if errors.Is(err, postgresrepository.ErrPaymentAmountMismatch) || errors.Is(err, postgresrepository.ErrCheckoutAlreadyPaid) {
+			return nil, err
+		}
+		return nil, errors.New("unable to verify payment, please try again later")
+	}
+	return payment, nil
+}
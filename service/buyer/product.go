@@ -0,0 +1,64 @@
+package buyer
+
+import (
+	"bambamload/logger"
+	"bambamload/models"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// GetProduct fetches a listed product priced for the quantity the buyer is looking at, or its minimum order quantity.
+// Its stock is shown less what other buyers hold in their checkouts.
+func (sb *ServiceBuyer) GetProduct(id string, quantity int64, user *models.User) (*models.BuyerProduct, error) {
+	product, err := sb.PostgresRepository.GetListedProduct(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("product does not exist")
+		}
+		return nil, errors.New("get product failed")
+	}
+
+	applyTierPrice(product, quantity)
+	sb.applyReservations(product, user)
+	product.ShowAvailableStock()
+
+	view := product.BuyerView()
+	return &view, nil
+}
+
+// GetProducts lists the products buyers can see. Only approved, active products of suppliers in good standing are listed.
+func (sb *ServiceBuyer) GetProducts(pm *models.PaginationMetadata, filter models.ProductFilter, quantity int64, user *models.User) ([]models.BuyerProduct, *models.PaginationMetadata, error) {
+	filter.SupplierID, filter.Status, filter.ListedOnly = "", "", true
+
+	products, paginationMetaData, err := sb.PostgresRepository.GetProducts(pm, filter)
+	if err != nil {
+		logger.Logger.Errorf("[GetProducts]Failed to get products: %v", err)
+		return nil, paginationMetaData, errors.New("unable to get products")
+	}
+
+	views := make([]models.BuyerProduct, 0, len(products))
+	for i := range products {
+		applyTierPrice(&products[i], quantity)
+		sb.applyReservations(&products[i], user)
+		products[i].ShowAvailableStock()
+		views = append(views, products[i].BuyerView())
+	}
+	return views, paginationMetaData, nil
+}
+
+// GetProductFacets counts the listed products matching a filter by category, type, fulfilment type and price band
+func (sb *ServiceBuyer) GetProductFacets(filter models.ProductFilter) (*models.ProductFacets, error) {
+	filter.SupplierID, filter.Status, filter.ListedOnly = "", "", true
+
+	facets, err := sb.PostgresRepository.GetProductFacets(filter)
+	if err != nil {
+		return nil, errors.New("unable to get product facets, please try again later")
+	}
+	return facets, nil
+}
+
+// applyTierPrice sets the product's unit price for a quantity, never below its minimum order quantity
+func applyTierPrice(product *models.Product, quantity int64) {
+	product.UnitPrice = product.UnitPriceFor(max(quantity, product.MinimumOrderQuantity, 1))
+}
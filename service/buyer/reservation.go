@@ -0,0 +1,105 @@
+package buyer
+
+import (
+	"bambamload/logger"
+	"bambamload/models"
+	"bambamload/service/redisService"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const stockReservationTTL = 10 * time.Minute
+
+// ReserveCheckout holds the stock for every line in the buyer's cart so it cannot be sold to another buyer
+// while this one checks out. The hold lapses on its own if the checkout is not completed in time.
+func (sb *ServiceBuyer) ReserveCheckout(user *models.User) (*models.CheckoutReservation, error) {
+	cart, err := sb.PostgresRepository.GetOrCreateCart(user.ID)
+	if err != nil {
+		logger.Logger.Errorf("[ReserveCheckout]Failed to get cart: %v", err)
+		return nil, errors.New("unable to reserve stock, please try again later")
+	}
+	if len(cart.CartItems) == 0 {
+		return nil, errors.New("cart is empty")
+	}
+
+	return sb.reserveCart(cart, user)
+}
+
+// ReleaseCheckout gives up the stock held for the buyer's checkout
+func (sb *ServiceBuyer) ReleaseCheckout(user *models.User) error {
+	if err := sb.RedisService.ReleaseStockReservations(user.ID); err != nil {
+		logger.Logger.Errorf("[ReleaseCheckout]Failed to release reservations for %s: %v", user.ID, err)
+		return errors.New("unable to release reserved stock, please try again later")
+	}
+	return nil
+}
+
+// reserveCart holds each cart line against what other buyers have not already reserved, dropping every hold
+// when one of them cannot be met
+func (sb *ServiceBuyer) reserveCart(cart *models.Cart, user *models.User) (*models.CheckoutReservation, error) {
+	reservation := &models.CheckoutReservation{ExpiresAt: time.Now().Add(stockReservationTTL)}
+
+	for _, item := range cart.CartItems {
+		product := item.Product
+		if err := validateCartQuantity(&product, item.VariantID, item.Quantity); err != nil {
+			sb.releaseReservations(user)
+			return nil, fmt.Errorf("%s: %v", product.Name, err)
+		}
+
+		available := product.AvailableQuantity()
+		if variant, ok := product.Variant(item.VariantID); ok {
+			available = min(available, variant.CurrentStockQuantity)
+		}
+
+		reserved, err := sb.RedisService.ReserveStock(redisService.StockItem(product.ID, item.VariantID), user.ID, item.Quantity, available, stockReservationTTL)
+		if err != nil {
+			logger.Logger.Errorf("[reserveCart]Failed to reserve %s for %s: %v", product.ID, user.ID, err)
+			sb.releaseReservations(user)
+			return nil, errors.New("unable to reserve stock, please try again later")
+		}
+		if !reserved {
+			sb.releaseReservations(user)
+			return nil, fmt.Errorf("%s: the remaining stock is held by other buyers checking out, please try again shortly", product.Name)
+		}
+
+		reservation.Items = append(reservation.Items, models.StockReservation{
+			ProductID: product.ID,
+			VariantID: item.VariantID,
+			Quantity:  item.Quantity,
+		})
+	}
+	return reservation, nil
+}
+
+// releaseReservations drops the buyer's holds once they no longer match the cart
+func (sb *ServiceBuyer) releaseReservations(user *models.User) {
+	if err := sb.RedisService.ReleaseStockReservations(user.ID); err != nil {
+		logger.Logger.Errorf("[releaseReservations]Failed to release reservations for %s: %v", user.ID, err)
+	}
+}
+
+// applyReservations takes the stock held in other buyers' checkouts off what a product and its variants have available
+func (sb *ServiceBuyer) applyReservations(product *models.Product, user *models.User) {
+	if !product.HasVariants() {
+		reserved, err := sb.RedisService.ReservedStock(redisService.StockItem(product.ID, ""), user.ID)
+		if err != nil {
+			logger.Logger.Errorf("[applyReservations]Failed to get reserved stock for %s: %v", product.ID, err)
+			return
+		}
+		product.ReservedQuantity = reserved
+		return
+	}
+
+	product.ReservedQuantity = 0
+	for i := range product.Variants {
+		variant := &product.Variants[i]
+		reserved, err := sb.RedisService.ReservedStock(redisService.StockItem(product.ID, variant.ID), user.ID)
+		if err != nil {
+			logger.Logger.Errorf("[applyReservations]Failed to get reserved stock for %s: %v", variant.ID, err)
+			continue
+		}
+		variant.ReservedQuantity = reserved
+		product.ReservedQuantity += reserved
+	}
+}
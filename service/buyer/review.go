@@ -0,0 +1,62 @@
+package buyer
+
+import (
+	"bambamload/constant"
+	"bambamload/logger"
+	"bambamload/models"
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// CreateReview rates a product the buyer received on one of their completed orders
+func (sb *ServiceBuyer) CreateReview(productID string, req models.CreateReviewRequest, user *models.User) (*models.Review, error) {
+	product, err := sb.PostgresRepository.GetProduct(productID, constant.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("product does not exist")
+		}
+		return nil, errors.New("unable to review product, please try again later")
+	}
+
+	completed, err := sb.PostgresRepository.HasCompletedOrderFor(user.ID, req.OrderID, product.ID)
+	if err != nil {
+		return nil, errors.New("unable to review product, please try again later")
+	}
+	if !completed {
+		return nil, errors.New("you can only review products from your completed orders")
+	}
+
+	exists, err := sb.PostgresRepository.ReviewExists(req.OrderID, product.ID)
+	if err != nil {
+		logger.Logger.Errorf("[CreateReview]Failed to check review for order %s: %v", req.OrderID, err)
+		return nil, errors.New("unable to review product, please try again later")
+	}
+	if exists {
+		return nil, errors.New("you have already reviewed this product for this order")
+	}
+
+	review := &models.Review{
+		ProductID: product.ID,
+		OrderID:   req.OrderID,
+		BuyerID:   user.ID,
+		BuyerName: user.Name,
+		Rating:    req.Rating,
+		Comment:   strings.TrimSpace(req.Comment),
+		Status:    constant.Published,
+	}
+	if err = sb.PostgresRepository.CreateReview(review); err != nil {
+		return nil, errors.New("unable to review product, please try again later")
+	}
+	return review, nil
+}
+
+// GetProductReviews lists the published reviews of a product
+func (sb *ServiceBuyer) GetProductReviews(pm *models.PaginationMetadata, productID string) ([]models.Review, *models.PaginationMetadata, error) {
+	reviews, paginationMetaData, err := sb.PostgresRepository.GetReviews(pm, models.ReviewFilter{ProductID: productID, Status: constant.Published})
+	if err != nil {
+		return nil, pm, errors.New("unable to get reviews, please try again later")
+	}
+	return reviews, paginationMetaData, nil
+}
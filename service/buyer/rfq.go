@@ -0,0 +1,201 @@
+package buyer
+
+import (
+	"bambamload/constant"
+	"bambamload/enum"
+	"bambamload/logger"
+	"bambamload/models"
+	"bambamload/service/postgresrepository"
+	"bambamload/utils"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func (sb *ServiceBuyer) CreateRfq(req models.CreateRfqRequest, user *models.User) (*models.Rfq, error) {
+	rfq := &models.Rfq{
+		Reference:       utils.GenerateReference("RFQ"),
+		BuyerID:         user.ID,
+		Category:        strings.TrimSpace(req.Category),
+		Quantity:        req.Quantity,
+		PaymentTerms:    req.PaymentTerms,
+		FulfilmentType:  req.FulfilmentType,
+		DeliveryAddress: req.DeliveryAddress,
+		Note:            req.Note,
+		Status:          constant.Open,
+	}
+
+	if req.ProductID != "" {
+		product, err := sb.PostgresRepository.GetProduct(req.ProductID, constant.ID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.New("product does not exist")
+			}
+			logger.Logger.Errorf("[CreateRfq]Failed to get product: %v", err)
+			return nil, errors.New("unable to create rfq, please try again later")
+		}
+		if product.ApprovalStatus != constant.Approved || product.Status != constant.Active {
+			return nil, errors.New("product is not available for purchase")
+		}
+		rfq.ProductID = product.ID
+		rfq.Category = product.Category
+	}
+
+	if req.RequiredBy != "" {
+		requiredBy, err := utils.DateStringToTime("2006-01-02", req.RequiredBy)
+		if err != nil {
+			return nil, errors.New("required_by must be in the format YYYY-MM-DD")
+		}
+		if requiredBy.Before(time.Now()) {
+			return nil, errors.New("required_by must be a future date")
+		}
+		rfq.RequiredBy = requiredBy
+	}
+
+	if err := sb.PostgresRepository.CreateRfq(rfq); err != nil {
+		return nil, errors.New("unable to create rfq, please try again later")
+	}
+	return rfq, nil
+}
+
+func (sb *ServiceBuyer) GetRfqs(pm *models.PaginationMetadata, status string, user *models.User) ([]models.Rfq, *models.PaginationMetadata, error) {
+	rfqs, paginationMetaData, err := sb.PostgresRepository.GetRfqs(pm, models.RfqFilter{
+		BuyerID: user.ID,
+		Status:  status,
+	})
+	if err != nil {
+		logger.Logger.Errorf("[GetRfqs]Failed to get buyer rfqs: %v", err)
+		return nil, pm, errors.New("unable to get rfqs")
+	}
+	return rfqs, paginationMetaData, nil
+}
+
+func (sb *ServiceBuyer) GetRfq(id string, user *models.User) (*models.Rfq, error) {
+	rfq, err := sb.PostgresRepository.GetRfq(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("rfq does not exist")
+		}
+		return nil, errors.New("unable to get rfq")
+	}
+	if rfq.BuyerID != user.ID {
+		return nil, errors.New("rfq does not exist")
+	}
+	return rfq, nil
+}
+
+func (sb *ServiceBuyer) CancelRfq(id string, user *models.User) error {
+	rfq, err := sb.GetRfq(id, user)
+	if err != nil {
+		return err
+	}
+	if rfq.Status != constant.Open {
+		return fmt.Errorf("a %s rfq cannot be cancelled", rfq.Status)
+	}
+
+	if err = sb.PostgresRepository.CancelRfq(rfq); err != nil {
+		return errors.New("unable to cancel rfq, please try again later")
+	}
+	return nil
+}
+
+// getQuote fetches a quote on one of the buyer's RFQs that is waiting on the buyer
+func (sb *ServiceBuyer) getQuote(id string, user *models.User) (*models.Quote, error) {
+	quote, err := sb.PostgresRepository.GetQuote(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("quote does not exist")
+		}
+		return nil, errors.New("unable to get quote")
+	}
+	if quote.Rfq == nil || quote.Rfq.BuyerID != user.ID {
+		return nil, errors.New("quote does not exist")
+	}
+
+	if quote.Status != constant.Pending {
+		return nil, fmt.Errorf("quote is %s", quote.Status)
+	}
+	if quote.AwaitingRole != enum.Buyer {
+		return nil, errors.New("quote is waiting on the supplier")
+	}
+	return quote, nil
+}
+
+// CounterQuote answers a supplier's quote with new terms
+func (sb *ServiceBuyer) CounterQuote(id string, req models.CounterOfferRequest, user *models.User) (*models.Quote, error) {
+	quote, err := sb.getQuote(id, user)
+	if err != nil {
+		return nil, err
+	}
+
+	offer, err := utils.BuildCounterOffer(quote, req, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = sb.PostgresRepository.CounterQuote(quote, offer, enum.Supplier); err != nil {
+		if errors.Is(err, postgresrepository.ErrQuoteChanged) {
+			return nil, err
+		}
+		return nil, errors.New("unable to counter quote, please try again later")
+	}
+
+	body := utils.BuildRfqUpdateEmail(quote.Supplier.BusinessName, quote.Rfq.Reference, "You have a counter-offer",
+		fmt.Sprintf("%s has countered your quote for %s at %s %d per %s for %d units.",
+			user.Name, quote.Product.Name, constant.NGN, offer.UnitPrice, quote.Product.Unit, offer.Quantity))
+	if err = sb.EmailService.Send(quote.Supplier.Email, fmt.Sprintf("Counter-offer - %s", quote.Rfq.Reference), body); err != nil {
+		logger.Logger.Errorf("[CounterQuote]Failed to send email for quote %s: %v", quote.ID, err)
+	}
+	return quote, nil
+}
+
+// AcceptQuote agrees to a supplier's quote and places the order at the quoted price
+func (sb *ServiceBuyer) AcceptQuote(id string, user *models.User) (*models.CheckoutResponse, error) {
+	quote, err := sb.getQuote(id, user)
+	if err != nil {
+		return nil, err
+	}
+
+	order := utils.BuildQuoteOrder(quote)
+	if err = sb.PostgresRepository.AcceptQuote(quote, order); err != nil {
+		if errors.Is(err, postgresrepository.ErrQuoteChanged) || errors.Is(err, postgresrepository.ErrInsufficientStock) ||
+			errors.Is(err, postgresrepository.ErrProductNotAvailable) {
+			return nil, err
+		}
+		return nil, errors.New("unable to accept quote, please try again later")
+	}
+
+	body := utils.BuildRfqUpdateEmail(quote.Supplier.BusinessName, quote.Rfq.Reference, "Your quote has been accepted",
+		fmt.Sprintf("%s has accepted your quote for %d units of %s. Order %s has been placed.",
+			user.Name, quote.Quantity, quote.Product.Name, order.Reference))
+	if err = sb.EmailService.Send(quote.Supplier.Email, fmt.Sprintf("Quote accepted - %s", quote.Rfq.Reference), body); err != nil {
+		logger.Logger.Errorf("[AcceptQuote]Failed to send email for quote %s: %v", quote.ID, err)
+	}
+
+	resp := &models.CheckoutResponse{Orders: []models.Order{*order}}
+	if order.PaymentTerms == constant.Prepayment {
+		resp.Payment, err = sb.InitializeCheckoutPayment(order.CheckoutReference, user)
+		if err != nil {
+			logger.Logger.Errorf("[AcceptQuote]Failed to initialize payment for %s: %v", order.CheckoutReference, err)
+		}
+	}
+	return resp, nil
+}
+
+func (sb *ServiceBuyer) RejectQuote(id string, user *models.User) error {
+	quote, err := sb.getQuote(id, user)
+	if err != nil {
+		return err
+	}
+
+	if err = sb.PostgresRepository.RejectQuote(quote); err != nil {
+		if errors.Is(err, postgresrepository.ErrQuoteChanged) {
+			return err
+		}
+		return errors.New("unable to reject quote, please try again later")
+	}
+	return nil
+}
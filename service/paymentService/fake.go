@@ -0,0 +1,134 @@
+package paymentService
+
+import (
+	"bambamload/constant"
+	"bambamload/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type fakePayment struct {
+	amount   int64
+	status   string
+	paidAt   time.Time
+	refunded int64
+}
+
+// FakeProvider is an in-memory payment gateway for local development and tests.
+// Payments are completed through SimulatePayment, which produces the same signed webhook a real gateway would send.
+type FakeProvider struct {
+	secret   string
+	baseURL  string
+	mu       sync.Mutex
+	payments map[string]*fakePayment
+}
+
+func NewFakeProvider(secret, baseURL string) *FakeProvider {
+	return &FakeProvider{
+		secret:   secret,
+		baseURL:  baseURL,
+		payments: make(map[string]*fakePayment),
+	}
+}
+
+func (fp *FakeProvider) Name() string {
+	return constant.Fake
+}
+
+func (fp *FakeProvider) Initialize(req models.InitializePaymentRequest) (*models.PaymentInitialization, error) {
+	if req.Amount <= 0 {
+		return nil, errors.New("amount must be greater than zero")
+	}
+
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+
+	fp.payments[req.Reference] = &fakePayment{amount: req.Amount, status: constant.Pending}
+
+	return &models.PaymentInitialization{
+		Reference:        req.Reference,
+		AuthorizationURL: fmt.Sprintf("%s/utilities/payments/fake/%s/pay", fp.baseURL, req.Reference),
+		Amount:           req.Amount,
+	}, nil
+}
+
+func (fp *FakeProvider) Verify(reference string) (*models.PaymentVerification, error) {
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+
+	payment, ok := fp.payments[reference]
+	if !ok {
+		return nil, errors.New("payment not found")
+	}
+
+	return &models.PaymentVerification{
+		Reference: reference,
+		Status:    payment.status,
+		Amount:    payment.amount,
+		PaidAt:    payment.paidAt,
+	}, nil
+}
+
+func (fp *FakeProvider) Refund(reference string, amount int64) (*models.PaymentRefund, error) {
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+
+	payment, ok := fp.payments[reference]
+	if !ok {
+		return nil, errors.New("payment not found")
+	}
+	if payment.status != constant.Success {
+		return nil, errors.New("only successful payments can be refunded")
+	}
+	if amount <= 0 || payment.refunded+amount > payment.amount {
+		return nil, errors.New("refund amount exceeds the amount paid")
+	}
+
+	payment.refunded += amount
+
+	return &models.PaymentRefund{
+		Reference:       reference,
+		RefundReference: fmt.Sprintf("RFD-%s-%d", reference, payment.refunded),
+		Status:          constant.Success,
+		Amount:          amount,
+	}, nil
+}
+
+func (fp *FakeProvider) VerifyWebhookSignature(payload []byte, signature string) bool {
+	return ValidSignature(fp.secret, payload, signature)
+}
+
+// SimulatePayment marks a payment as paid or failed and returns the signed webhook payload for it
+func (fp *FakeProvider) SimulatePayment(reference string, success bool) ([]byte, string, error) {
+	fp.mu.Lock()
+	payment, ok := fp.payments[reference]
+	if !ok {
+		fp.mu.Unlock()
+		return nil, "", errors.New("payment not found")
+	}
+
+	event := models.PaymentWebhookEvent{
+		Event:     constant.ChargeFailed,
+		Reference: reference,
+		Status:    constant.Failed,
+		Amount:    payment.amount,
+	}
+	if success {
+		payment.status = constant.Success
+		payment.paidAt = time.Now().UTC()
+		event.Event = constant.ChargeSuccess
+		event.Status = constant.Success
+	} else {
+		payment.status = constant.Failed
+	}
+	fp.mu.Unlock()
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, "", err
+	}
+	return payload, Sign(fp.secret, payload), nil
+}
@@ -0,0 +1,70 @@
+package paymentService
+
+import (
+	"bambamload/constant"
+	"bambamload/logger"
+	"bambamload/models"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"os"
+)
+
+// PaymentProvider is implemented by every payment gateway the API can collect payments through
+type PaymentProvider interface {
+	Name() string
+	Initialize(req models.InitializePaymentRequest) (*models.PaymentInitialization, error)
+	Verify(reference string) (*models.PaymentVerification, error)
+	Refund(reference string, amount int64) (*models.PaymentRefund, error)
+	VerifyWebhookSignature(payload []byte, signature string) bool
+}
+
+// ErrPaymentsUnavailable is returned by everything that needs a provider when none is configured
+var ErrPaymentsUnavailable = errors.New("payments are unavailable at the moment, please try again later")
+
+// NewPaymentProvider returns the provider selected by PAYMENT_PROVIDER. The local fake provider is only
+// available in development, where it is also the default. Without a usable provider it returns nil and the
+// API runs with payments unavailable; only an unknown provider name stops startup.
+func NewPaymentProvider() PaymentProvider {
+	provider := os.Getenv("PAYMENT_PROVIDER")
+	development := os.Getenv(constant.AppEnv) == constant.Development
+	if provider == "" && development {
+		provider = constant.Fake
+	}
+
+	switch provider {
+	case "":
+		logger.Logger.Errorf("PAYMENT_PROVIDER is not set, payments are unavailable")
+		return nil
+	case constant.Fake:
+		if !development {
+			logger.Logger.Errorf("the fake payment provider is only available in development, payments are unavailable")
+			return nil
+		}
+	default:
+		logger.Logger.Fatalf("unsupported payment provider: %s", provider)
+		return nil
+	}
+
+	secret := os.Getenv("PAYMENT_WEBHOOK_SECRET")
+	if secret == "" {
+		logger.Logger.Fatalf("PAYMENT_WEBHOOK_SECRET is not set")
+		return nil
+	}
+
+	logger.Logger.Infof("using fake payment provider")
+	return NewFakeProvider(secret, os.Getenv("APP_URL"))
+}
+
+// Sign returns the hex encoded HMAC-SHA512 of a webhook payload
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha512.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ValidSignature compares a webhook signature against the expected one in constant time
+func ValidSignature(secret string, payload []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, payload)), []byte(signature))
+}
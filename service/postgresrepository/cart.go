@@ -0,0 +1,73 @@
+package postgresrepository
+
+import (
+	"bambamload/logger"
+	"bambamload/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetOrCreateCart fetches the buyer's cart with its items, creating an empty cart if none exists
+func (p *PostgresRepository) GetOrCreateCart(buyerID string) (*models.Cart, error) {
+	cart := &models.Cart{BuyerID: buyerID}
+
+	err := p.db.Where("buyer_id = ?", buyerID).FirstOrCreate(cart).Error
+	if err != nil {
+		logger.Logger.Errorf("[GetOrCreateCart]error getting cart for buyer %s: %s", buyerID, err)
+		return nil, err
+	}
+
+	err = p.db.Preload("CartItems", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at asc")
+	}).Preload("CartItems.Product").Preload("CartItems.Product.ProductUploads").Preload("CartItems.Product.PriceTiers").Preload("CartItems.Product.Variants").
+		Where("id = ?", cart.ID).First(cart).Error
+	if err != nil {
+		logger.Logger.Errorf("[GetOrCreateCart]error loading cart items for cart %s: %s", cart.ID, err)
+		return nil, err
+	}
+
+	return cart, nil
+}
+
+func (p *PostgresRepository) GetCartItem(id, cartID string) (*models.CartItem, error) {
+	var item *models.CartItem
+
+	err := p.db.Preload(clause.Associations).Preload("Product.PriceTiers").Preload("Product.Variants").Where("id = ? AND cart_id = ?", id, cartID).First(&item).Error
+	if err != nil {
+		logger.Logger.Errorf("[GetCartItem]error getting cart item %s: %s", id, err)
+		return nil, err
+	}
+	return item, nil
+}
+
+func (p *PostgresRepository) CreateCartItem(item *models.CartItem) error {
+	return p.db.Create(item).Error
+}
+
+func (p *PostgresRepository) UpdateCartItem(id string, updates map[string]interface{}) error {
+	err := p.db.Model(&models.CartItem{}).Where("id = ?", id).Updates(updates).Error
+	if err != nil {
+		logger.Logger.Errorf("[UpdateCartItem]error updating cart item %s: %s", id, err)
+		return err
+	}
+	return nil
+}
+
+func (p *PostgresRepository) DeleteCartItem(id, cartID string) error {
+	err := p.db.Where("id = ? AND cart_id = ?", id, cartID).Delete(&models.CartItem{}).Error
+	if err != nil {
+		logger.Logger.Errorf("[DeleteCartItem]error deleting cart item %s: %s", id, err)
+		return err
+	}
+	return nil
+}
+
+func (p *PostgresRepository) ClearCart(cartID string) error {
+	err := p.db.Where("cart_id = ?", cartID).Delete(&models.CartItem{}).Error
+	if err != nil {
+		logger.Logger.Errorf("[ClearCart]error clearing cart %s: %s", cartID, err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+package postgresrepository
+
+import (
+	"bambamload/constant"
+	"bambamload/logger"
+	"bambamload/models"
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const maxCategoryDepth = 10
+
+var (
+	ErrCategoryNotFound = errors.New("category is not in the category list")
+	ErrCategoryInactive = errors.New("category is no longer in use")
+	ErrCategoryNotLeaf  = errors.New("please choose a subcategory of this category")
+)
+
+func (p *PostgresRepository) CreateCategory(category *models.Category) error {
+	if err := p.db.Create(category).Error; err != nil {
+		logger.Logger.Errorf("[CreateCategory]error creating category %s: %s", category.Name, err)
+		return err
+	}
+	return nil
+}
+
+func (p *PostgresRepository) GetCategory(id string) (*models.Category, error) {
+	var category *models.Category
+
+	if err := p.db.Where("id = ?", id).First(&category).Error; err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// GetCategories lists every category, or only active ones, in name order
+func (p *PostgresRepository) GetCategories(activeOnly bool) ([]models.Category, error) {
+	var categories []models.Category
+
+	query := p.db.Model(&models.Category{}).Order("name asc")
+	if activeOnly {
+		query = query.Where("status = ?", constant.Active)
+	}
+
+	if err := query.Find(&categories).Error; err != nil {
+		logger.Logger.Errorf("[GetCategories]error getting categories: %s", err)
+		return nil, err
+	}
+	return categories, nil
+}
+
+// CategoryPath returns a category and its ancestors, top-level category first
+func (p *PostgresRepository) CategoryPath(id string) ([]models.Category, error) {
+	var path []models.Category
+
+	for id != "" {
+		if len(path) == maxCategoryDepth {
+			return nil, errors.New("category tree is too deep")
+		}
+
+		category, err := p.GetCategory(id)
+		if err != nil {
+			return nil, err
+		}
+		path = append([]models.Category{*category}, path...)
+		id = category.ParentID
+	}
+	return path, nil
+}
+
+// ResolveCategory finds where a product belongs in the category tree, by category id or else by the names of
+// its top-level category and, optionally, a subcategory of it. It returns the path from the top-level category
+// down to the category the product is filed under, which must be active and have no active subcategories.
+func (p *PostgresRepository) ResolveCategory(categoryID, category, typeName string) ([]models.Category, error) {
+	var (
+		path []models.Category
+		err  error
+	)
+
+	if categoryID != "" {
+		path, err = p.CategoryPath(categoryID)
+	} else {
+		var root, child *models.Category
+		root, err = p.FindCategory("", category)
+		if err == nil {
+			path = append(path, *root)
+			if typeName != "" && !strings.EqualFold(strings.TrimSpace(typeName), root.Name) {
+				child, err = p.FindCategory(root.ID, typeName)
+				if err == nil {
+					path = append(path, *child)
+				}
+			}
+		}
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, err
+	}
+
+	for _, node := range path {
+		if node.Status != constant.Active {
+			return nil, ErrCategoryInactive
+		}
+	}
+
+	hasSubcategories, err := p.HasActiveSubcategories(path[len(path)-1].ID)
+	if err != nil {
+		return nil, err
+	}
+	if hasSubcategories {
+		return nil, ErrCategoryNotLeaf
+	}
+	return path, nil
+}
+
+// FindCategory finds a category under a parent, or at the top level when parentID is empty, by its name in any case
+func (p *PostgresRepository) FindCategory(parentID, name string) (*models.Category, error) {
+	var category *models.Category
+
+	err := p.db.Where("parent_id = ? AND LOWER(name) = LOWER(TRIM(?))", parentID, name).First(&category).Error
+	if err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// CategoryNameTaken reports whether another category under the same parent already has the name
+func (p *PostgresRepository) CategoryNameTaken(parentID, name, excludeID string) (bool, error) {
+	var count int64
+
+	query := p.db.Model(&models.Category{}).Where("parent_id = ? AND LOWER(name) = LOWER(TRIM(?))", parentID, name)
+	if excludeID != "" {
+		query = query.Where("id <> ?", excludeID)
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// HasActiveSubcategories reports whether a category has active children, in which case products go under one of them
+func (p *PostgresRepository) HasActiveSubcategories(id string) (bool, error) {
+	var count int64
+
+	err := p.db.Model(&models.Category{}).Where("parent_id = ? AND status = ?", id, constant.Active).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// CategorySubtree returns the ids of a category and every category below it
+func (p *PostgresRepository) CategorySubtree(id string) ([]string, error) {
+	return categorySubtree(p.db, id)
+}
+
+func categorySubtree(tx *gorm.DB, id string) ([]string, error) {
+	var ids []string
+
+	err := tx.Raw(`
+		WITH RECURSIVE subtree AS (
+			SELECT id::text AS id FROM categories WHERE id::text = $1
+			UNION ALL
+			SELECT c.id::text FROM categories c JOIN subtree s ON c.parent_id = s.id
+		)
+		SELECT id FROM subtree
+	`, id).Scan(&ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// UpdateCategory updates a category and refreshes the category names held on products filed anywhere below it,
+// since a rename or a move changes them. rootName is the name of the category's top-level category after the update.
+func (p *PostgresRepository) UpdateCategory(id string, updates map[string]interface{}, rootName string) error {
+	err := p.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Category{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		if name, ok := updates["name"]; ok {
+			if err := tx.Model(&models.Product{}).Where("category_id = ?", id).Update("type", name).Error; err != nil {
+				return err
+			}
+		}
+
+		ids, err := categorySubtree(tx, id)
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.Product{}).Where("category_id IN ?", ids).Update("category", rootName).Error
+	})
+	if err != nil {
+		logger.Logger.Errorf("[UpdateCategory]error updating category %s: %s", id, err)
+		return err
+	}
+	return nil
+}
+
+// CategoryUsage counts the subcategories of a category and the products filed under it
+func (p *PostgresRepository) CategoryUsage(id string) (children, products int64, err error) {
+	if err = p.db.Model(&models.Category{}).Where("parent_id = ?", id).Count(&children).Error; err != nil {
+		return 0, 0, err
+	}
+	if err = p.db.Model(&models.Product{}).Where("category_id = ?", id).Count(&products).Error; err != nil {
+		return 0, 0, err
+	}
+	return children, products, nil
+}
+
+func (p *PostgresRepository) DeleteCategory(id string) error {
+	if err := p.db.Where("id = ?", id).Delete(&models.Category{}).Error; err != nil {
+		logger.Logger.Errorf("[DeleteCategory]error deleting category %s: %s", id, err)
+		return err
+	}
+	return nil
+}
+
+// GetUnmappedCategories lists the free-text category and type pairs of products not yet in the category tree.
+// Pairs that differ only in case or surrounding spaces are counted together.
+func (p *PostgresRepository) GetUnmappedCategories() ([]models.UnmappedCategory, error) {
+	var unmapped []models.UnmappedCategory
+
+	err := p.db.Model(&models.Product{}).
+		Select("MIN(TRIM(COALESCE(category, ''))) AS category, MIN(TRIM(COALESCE(type, ''))) AS type, COUNT(*) AS products").
+		Where("COALESCE(category_id, '') = ''").
+		Group("LOWER(TRIM(COALESCE(category, ''))), LOWER(TRIM(COALESCE(type, '')))").
+		Order("products desc").
+		Scan(&unmapped).Error
+	if err != nil {
+		logger.Logger.Errorf("[GetUnmappedCategories]error getting unmapped categories: %s", err)
+		return nil, err
+	}
+	return unmapped, nil
+}
+
+// MapProductCategory files the unmapped products with a free-text category and type pair under a tree category,
+// taking on its top-level category name and its own name
+func (p *PostgresRepository) MapProductCategory(mapping models.CategoryMapping, rootName, name string) (int64, error) {
+	result := p.db.Model(&models.Product{}).
+		Where("COALESCE(category_id, '') = '' AND LOWER(TRIM(COALESCE(category, ''))) = LOWER(TRIM(?)) AND LOWER(TRIM(COALESCE(type, ''))) = LOWER(TRIM(?))", mapping.Category, mapping.Type).
+		Updates(map[string]interface{}{
+			"category_id": mapping.CategoryID,
+			"category":    rootName,
+			"type":        name,
+		})
+	if result.Error != nil {
+		logger.Logger.Errorf("[MapProductCategory]error mapping %s/%s: %s", mapping.Category, mapping.Type, result.Error)
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
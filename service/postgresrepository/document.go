@@ -0,0 +1,31 @@
+package postgresrepository
+
+import (
+	"bambamload/logger"
+	"bambamload/models"
+
+	"gorm.io/gorm/clause"
+)
+
+func (p *PostgresRepository) GetOrderDocument(orderID, docType string) (*models.OrderDocument, error) {
+	var document *models.OrderDocument
+
+	err := p.db.Where("order_id = ? AND type = ?", orderID, docType).First(&document).Error
+	if err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// SaveOrderDocument stores a generated document, replacing any earlier copy of the same type for the order
+func (p *PostgresRepository) SaveOrderDocument(document *models.OrderDocument) error {
+	err := p.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "order_id"}, {Name: "type"}},
+		DoUpdates: clause.AssignmentColumns([]string{"number", "file_url", "file_type", "updated_at"}),
+	}).Create(document).Error
+	if err != nil {
+		logger.Logger.Errorf("[SaveOrderDocument]error saving %s for order %s: %s", document.Type, document.OrderID, err)
+		return err
+	}
+	return nil
+}
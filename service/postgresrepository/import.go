@@ -0,0 +1,82 @@
+package postgresrepository
+
+import (
+	"bambamload/constant"
+	"bambamload/logger"
+	"bambamload/models"
+	"time"
+)
+
+func (p *PostgresRepository) CreateProductImport(productImport *models.ProductImport) error {
+	if err := p.db.Create(productImport).Error; err != nil {
+		logger.Logger.Errorf("[CreateProductImport]error saving import for %s: %s", productImport.SupplierID, err)
+		return err
+	}
+	return nil
+}
+
+func (p *PostgresRepository) UpdateProductImport(id string, updates map[string]interface{}) error {
+	if err := p.db.Model(&models.ProductImport{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		logger.Logger.Errorf("[UpdateProductImport]error updating import %s: %s", id, err)
+		return err
+	}
+	return nil
+}
+
+// ClaimProductImport moves a pending import to processing, reporting false when it has already been picked up
+func (p *PostgresRepository) ClaimProductImport(id string) (bool, error) {
+	res := p.db.Model(&models.ProductImport{}).Where("id = ? AND status = ?", id, constant.Pending).
+		Update("status", constant.Processing)
+	if res.Error != nil {
+		logger.Logger.Errorf("[ClaimProductImport]error claiming import %s: %s", id, res.Error)
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+// FailProductImport fails an import that is still processing, reporting false when it has already finished
+func (p *PostgresRepository) FailProductImport(id, failureReason string) (bool, error) {
+	res := p.db.Model(&models.ProductImport{}).Where("id = ? AND status = ?", id, constant.Processing).
+		Updates(map[string]interface{}{"status": constant.Failed, "failure_reason": failureReason})
+	if res.Error != nil {
+		logger.Logger.Errorf("[FailProductImport]error failing import %s: %s", id, res.Error)
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+// GetStaleProductImports lists the pending and processing imports not touched since before, oldest first
+func (p *PostgresRepository) GetStaleProductImports(before time.Time) ([]models.ProductImport, error) {
+	var imports []models.ProductImport
+
+	err := p.db.Where("status IN ? AND updated_at < ?", []string{constant.Pending, constant.Processing}, before).
+		Order("created_at asc").Find(&imports).Error
+	if err != nil {
+		logger.Logger.Errorf("[GetStaleProductImports]error getting stale imports: %s", err)
+		return nil, err
+	}
+	return imports, nil
+}
+
+func (p *PostgresRepository) GetProductImport(id, supplierID string) (*models.ProductImport, error) {
+	var productImport *models.ProductImport
+
+	err := p.db.Where("id = ? AND supplier_id = ?", id, supplierID).First(&productImport).Error
+	if err != nil {
+		return nil, err
+	}
+	return productImport, nil
+}
+
+func (p *PostgresRepository) GetProductImports(pm *models.PaginationMetadata, supplierID string) ([]models.ProductImport, *models.PaginationMetadata, error) {
+	var imports []models.ProductImport
+
+	query := p.db.Model(&models.ProductImport{}).Where("supplier_id = ?", supplierID).Order("created_at desc")
+
+	err := query.Scopes(Paginator(pm, &models.ProductImport{}, query)).Find(&imports).Error
+	if err != nil {
+		logger.Logger.Errorf("[GetProductImports]error getting imports for %s: %s", supplierID, err)
+		return nil, pm, err
+	}
+	return imports, pm, nil
+}
@@ -0,0 +1,38 @@
+package postgresrepository
+
+import (
+	"bambamload/logger"
+	"bambamload/models"
+	"time"
+)
+
+func (p *PostgresRepository) GetNotifications(pm *models.PaginationMetadata, userID string, unreadOnly bool) ([]models.Notification, *models.PaginationMetadata, error) {
+	var notifications []models.Notification
+
+	query := p.db.Model(&models.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	query = query.Order("created_at desc")
+
+	err := query.Scopes(Paginator(pm, &models.Notification{}, query)).Find(&notifications).Error
+	if err != nil {
+		logger.Logger.Errorf("[GetNotifications]error getting notifications for %s: %s", userID, err)
+		return nil, pm, err
+	}
+	return notifications, pm, nil
+}
+
+// MarkNotificationsRead marks a user's notifications as read, all of them when no ids are given
+func (p *PostgresRepository) MarkNotificationsRead(userID string, ids []string) error {
+	query := p.db.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID)
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+
+	if err := query.Update("read_at", time.Now()).Error; err != nil {
+		logger.Logger.Errorf("[MarkNotificationsRead]error marking notifications read for %s: %s", userID, err)
+		return err
+	}
+	return nil
+}
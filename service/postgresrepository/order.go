@@ -0,0 +1,331 @@
+package postgresrepository
+
+import (
+	"bambamload/constant"
+	"bambamload/enum"
+	"bambamload/logger"
+	"bambamload/models"
+	"bambamload/utils"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInsufficientStock   = errors.New("insufficient stock")
+	ErrProductNotAvailable = errors.New("product not available")
+	ErrOrderStatusChanged  = errors.New("order status has changed, please refresh and try again")
+)
+
+// PlaceOrders creates the orders from a checkout, decrementing product stock and clearing the cart in one transaction
+func (p *PostgresRepository) PlaceOrders(cartID string, orders []models.Order) error {
+	return p.db.Transaction(func(tx *gorm.DB) error {
+		if err := p.placeOrders(tx, orders); err != nil {
+			return err
+		}
+
+		return tx.Where("cart_id = ?", cartID).Delete(&models.CartItem{}).Error
+	})
+}
+
+// placeOrders checks and decrements stock for the order items, then creates the orders with their first history entry
+func (p *PostgresRepository) placeOrders(tx *gorm.DB, orders []models.Order) error {
+	for _, order := range orders {
+		for _, item := range order.OrderItems {
+			var product models.Product
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", item.ProductID).First(&product).Error
+			if err != nil {
+				return err
+			}
+
+			if product.ApprovalStatus != constant.Approved || product.Status != constant.Active {
+				return fmt.Errorf("%w: %s", ErrProductNotAvailable, product.Name)
+			}
+			if product.AvailableQuantity() < item.Quantity {
+				return fmt.Errorf("%w: %s", ErrInsufficientStock, product.Name)
+			}
+
+			if item.VariantID != "" {
+				var variant models.ProductVariant
+				err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ? AND product_id = ?", item.VariantID, product.ID).First(&variant).Error
+				if err != nil {
+					return err
+				}
+				if variant.Status != constant.Active {
+					return fmt.Errorf("%w: %s %s", ErrProductNotAvailable, product.Name, variant.Name)
+				}
+				if variant.CurrentStockQuantity < item.Quantity {
+					return fmt.Errorf("%w: %s %s", ErrInsufficientStock, product.Name, variant.Name)
+				}
+			}
+
+			err = p.PostStockMovement(tx, &models.StockMovement{
+				ProductID: product.ID,
+				VariantID: item.VariantID,
+				Type:      constant.Sale,
+				Quantity:  -item.Quantity,
+				Reference: order.Reference,
+				CreatedBy: order.BuyerID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := tx.Create(&orders).Error; err != nil {
+		return err
+	}
+
+	history := make([]models.OrderStatusHistory, 0, len(orders))
+	for _, order := range orders {
+		history = append(history, models.OrderStatusHistory{
+			OrderID:       order.ID,
+			ToStatus:      order.Status,
+			ChangedBy:     order.BuyerID,
+			ChangedByRole: enum.Buyer,
+			Note:          "order placed",
+		})
+	}
+	return tx.Create(&history).Error
+}
+
+// publicUserColumns are the details of a buyer or supplier the other side of an order or quote may see
+var publicUserColumns = []string{"id", "name", "email", "phone_number", "business_name", "address", "state", "country"}
+
+// selectPublicUser limits a preloaded buyer or supplier to its public columns
+func selectPublicUser(db *gorm.DB) *gorm.DB {
+	return db.Select(publicUserColumns)
+}
+
+// GetOrder fetches an order with its items, history, shipments and the public details of its buyer and supplier
+func (p *PostgresRepository) GetOrder(id, identifier string) (*models.Order, error) {
+	var (
+		order *models.Order
+		err   error
+	)
+
+	query := p.db.Preload("OrderItems").Preload("Buyer", selectPublicUser).Preload("Supplier", selectPublicUser).
+		Preload("StatusHistory", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at asc")
+		}).Preload("Shipments").Preload("Shipments.ShipmentItems")
+
+	switch identifier {
+	case constant.ID:
+		err = query.Where("id = ?", id).First(&order).Error
+
+	case constant.Reference:
+		err = query.Where("reference = ?", id).First(&order).Error
+
+	default:
+		return nil, errors.New("identifier is not valid")
+	}
+
+	if err != nil {
+		logger.Logger.Errorf("error getting order by %s: %s", identifier, err)
+		return order, err
+	}
+
+	return order, nil
+}
+
+func (p *PostgresRepository) GetOrders(pm *models.PaginationMetadata, filter models.OrderFilter) ([]models.Order, *models.PaginationMetadata, error) {
+	var orders []models.Order
+
+	query := p.db.Model(&models.Order{}).Order("created_at desc")
+
+	if filter.BuyerID != "" {
+		query = query.Where("buyer_id = ?", filter.BuyerID)
+	}
+
+	if filter.SupplierID != "" {
+		query = query.Where("supplier_id = ?", filter.SupplierID)
+	}
+
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+
+	if filter.MinAmount > 0 {
+		query = query.Where("total_amount >= ?", filter.MinAmount)
+	}
+
+	if filter.MaxAmount > 0 {
+		query = query.Where("total_amount <= ?", filter.MaxAmount)
+	}
+
+	err := query.Scopes(Paginator(pm, &models.Order{}, query)).Preload("OrderItems").Find(&orders).Error
+	if err != nil {
+		return nil, pm, err
+	}
+
+	return orders, pm, nil
+}
+
+// ChangeOrderStatus moves an order to a new status and records the change in the order status history.
+// afterChange, when provided, runs inside the same transaction so side effects commit or roll back with the status.
+func (p *PostgresRepository) ChangeOrderStatus(change models.OrderStatusChange, afterChange func(tx *gorm.DB) error) error {
+	return p.ChangeOrderStatuses([]models.OrderStatusChange{change}, afterChange)
+}
+
+// ChangeOrderStatuses applies several status changes in order within one transaction, so an order moved through
+// more than one status never stops part way. afterChange runs last, inside the same transaction.
+func (p *PostgresRepository) ChangeOrderStatuses(changes []models.OrderStatusChange, afterChange func(tx *gorm.DB) error) error {
+	//status of each order as the changes are applied, the orders themselves are only updated once committed
+	statuses := make(map[string]string)
+
+	err := p.db.Transaction(func(tx *gorm.DB) error {
+		for _, change := range changes {
+			order := change.Order
+			from, ok := statuses[order.ID]
+			if !ok {
+				from = order.Status
+			}
+
+			updates := map[string]interface{}{"status": change.Status}
+			for k, v := range change.Updates {
+				updates[k] = v
+			}
+
+			//guard against a concurrent change since the order was read
+			res := tx.Model(&models.Order{}).Where("id = ? AND status = ?", order.ID, from).Updates(updates)
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return ErrOrderStatusChanged
+			}
+
+			history := &models.OrderStatusHistory{
+				OrderID:    order.ID,
+				FromStatus: from,
+				ToStatus:   change.Status,
+				Note:       change.Note,
+				IsOverride: change.Override,
+			}
+			if change.ChangedBy != nil {
+				history.ChangedBy = change.ChangedBy.ID
+				history.ChangedByName = change.ChangedBy.Name
+				history.ChangedByRole = change.ChangedBy.Role
+			}
+			if err := tx.Create(history).Error; err != nil {
+				return err
+			}
+
+			if change.Status == constant.Completed {
+				if err := p.recordSettlement(tx, order); err != nil {
+					return err
+				}
+			}
+			statuses[order.ID] = change.Status
+		}
+
+		if afterChange != nil {
+			return afterChange(tx)
+		}
+		return nil
+	})
+	if err != nil {
+		for _, change := range changes {
+			logger.Logger.Errorf("[ChangeOrderStatuses]error moving order %s from %s to %s: %s", change.Order.ID, change.Order.Status, change.Status, err)
+		}
+		return err
+	}
+
+	for _, change := range changes {
+		change.Order.Status = change.Status
+	}
+	return nil
+}
+
+// RestoreOrderStock returns the unshipped quantities of an order's items to stock through the stock ledger.
+// Backordered units were taken at checkout but were not on hand, so they are only released from the product's backorder.
+func (p *PostgresRepository) RestoreOrderStock(tx *gorm.DB, order *models.Order) error {
+	var items []models.OrderItem
+	if err := tx.Where("order_id = ?", order.ID).Find(&items).Error; err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if item.BackorderedQuantity > 0 {
+			err := tx.Model(&models.Product{}).Where("id = ?", item.ProductID).
+				Update("backordered_quantity", gorm.Expr("backordered_quantity - ?", item.BackorderedQuantity)).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		restored := item.OutstandingQuantity() - item.BackorderedQuantity
+		if restored <= 0 {
+			continue
+		}
+
+		err := p.PostStockMovement(tx, &models.StockMovement{
+			ProductID: item.ProductID,
+			VariantID: item.VariantID,
+			Type:      constant.Return,
+			Quantity:  restored,
+			Reference: order.Reference,
+			Reason:    "order closed before shipping",
+			CreatedBy: order.BuyerID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CancelOrder moves an order to cancelled, or rejected when the change says so, returning stock that has not left
+// the supplier and, when the order has been paid for, recording a refund of its total. The refund is nil for unpaid orders.
+func (p *PostgresRepository) CancelOrder(change models.OrderStatusChange, refundMethod string) (*models.Refund, error) {
+	var (
+		order  = change.Order
+		refund *models.Refund
+	)
+
+	updates := map[string]interface{}{}
+	for k, v := range change.Updates {
+		updates[k] = v
+	}
+	if order.PaymentStatus == constant.Paid {
+		updates["payment_status"] = constant.Refunded
+	}
+	if change.Status == "" {
+		change.Status = constant.Cancelled
+	}
+	change.Updates = updates
+
+	err := p.ChangeOrderStatus(change, func(tx *gorm.DB) error {
+		//only what is still outstanding is restored, so partly shipped orders are covered too
+		if err := p.RestoreOrderStock(tx, order); err != nil {
+			return err
+		}
+
+		if order.PaymentStatus != constant.Paid || order.TotalAmount <= 0 {
+			return nil
+		}
+
+		var err error
+		refund, err = p.createRefund(tx, order, utils.ToKobo(order.TotalAmount), refundMethod, change.Note)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if order.PaymentStatus == constant.Paid {
+		order.PaymentStatus = constant.Refunded
+	}
+	return refund, nil
+}
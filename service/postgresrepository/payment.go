@@ -0,0 +1,228 @@
+package postgresrepository
+
+import (
+	"bambamload/constant"
+	"bambamload/logger"
+	"bambamload/models"
+	"bambamload/utils"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrPaymentAmountMismatch = errors.New("amount paid does not match the amount due")
+	ErrCheckoutAlreadyPaid   = errors.New("this checkout has already been paid for")
+)
+
+func (p *PostgresRepository) CreatePayment(payment *models.Payment) error {
+	return p.db.Create(payment).Error
+}
+
+// OpenCheckoutPayment returns the checkout's pending payment when it is for the same amount and has been started
+// with the provider, and otherwise saves payment as the checkout's payment, failing any pending one it replaces.
+// The checkout's orders are locked so concurrent calls cannot both open a payment. It reports whether payment was saved.
+func (p *PostgresRepository) OpenCheckoutPayment(payment *models.Payment) (*models.Payment, bool, error) {
+	var (
+		open    models.Payment
+		created bool
+	)
+
+	err := p.db.Transaction(func(tx *gorm.DB) error {
+		var orders []models.Order
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("checkout_reference = ?", payment.CheckoutReference).Find(&orders).Error
+		if err != nil {
+			return err
+		}
+
+		var paid int64
+		err = tx.Model(&models.Payment{}).Where("checkout_reference = ? AND status = ?", payment.CheckoutReference, constant.Success).Count(&paid).Error
+		if err != nil {
+			return err
+		}
+		if paid > 0 {
+			return ErrCheckoutAlreadyPaid
+		}
+
+		err = tx.Where("checkout_reference = ? AND status = ?", payment.CheckoutReference, constant.Pending).Order("created_at desc").First(&open).Error
+		if err == nil && open.Amount == payment.Amount && open.AuthorizationURL != "" {
+			return nil
+		}
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		err = tx.Model(&models.Payment{}).Where("checkout_reference = ? AND status = ?", payment.CheckoutReference, constant.Pending).
+			Update("status", constant.Failed).Error
+		if err != nil {
+			return err
+		}
+
+		created = true
+		return tx.Create(payment).Error
+	})
+	if err != nil {
+		if !errors.Is(err, ErrCheckoutAlreadyPaid) {
+			logger.Logger.Errorf("[OpenCheckoutPayment]error opening payment for checkout %s: %s", payment.CheckoutReference, err)
+		}
+		return nil, false, err
+	}
+
+	if created {
+		return payment, true, nil
+	}
+	return &open, false, nil
+}
+
+func (p *PostgresRepository) UpdatePayment(reference string, updates map[string]interface{}) error {
+	err := p.db.Model(&models.Payment{}).Where("reference = ?", reference).Updates(updates).Error
+	if err != nil {
+		logger.Logger.Errorf("[UpdatePayment]error updating payment %s: %s", reference, err)
+		return err
+	}
+	return nil
+}
+
+func (p *PostgresRepository) GetPayment(reference string) (*models.Payment, error) {
+	var payment *models.Payment
+
+	err := p.db.Where("reference = ?", reference).First(&payment).Error
+	if err != nil {
+		logger.Logger.Errorf("[GetPayment]error getting payment %s: %s", reference, err)
+		return nil, err
+	}
+	return payment, nil
+}
+
+// GetOrdersByCheckoutReference fetches every order placed in one checkout
+func (p *PostgresRepository) GetOrdersByCheckoutReference(checkoutReference string) ([]models.Order, error) {
+	var orders []models.Order
+
+	err := p.db.Preload("OrderItems").Where("checkout_reference = ?", checkoutReference).Order("created_at asc").Find(&orders).Error
+	if err != nil {
+		logger.Logger.Errorf("[GetOrdersByCheckoutReference]error getting orders for %s: %s", checkoutReference, err)
+		return nil, err
+	}
+	return orders, nil
+}
+
+// ApplyPaymentVerification records the outcome of a payment reported by the provider.
+// A successful payment marks the checkout's open orders as paid and moves the funds from the clearing wallet
+// into the platform wallet, where they are held until the orders settle. Orders cancelled or rejected in the
+// meantime stay unpaid and get a pending refund. Repeated calls are no-ops.
+func (p *PostgresRepository) ApplyPaymentVerification(verification *models.PaymentVerification) (*models.Payment, error) {
+	var payment models.Payment
+
+	err := p.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("reference = ?", verification.Reference).First(&payment).Error
+		if err != nil {
+			return err
+		}
+
+		if payment.Status == constant.Success {
+			return nil
+		}
+
+		switch verification.Status {
+		case constant.Success:
+			if verification.Amount < payment.Amount {
+				return ErrPaymentAmountMismatch
+			}
+
+			//a second payment that went through for the same checkout is left for a refund, never credited twice
+			var paid int64
+			err = tx.Model(&models.Payment{}).Where("checkout_reference = ? AND status = ? AND id <> ?", payment.CheckoutReference, constant.Success, payment.ID).
+				Count(&paid).Error
+			if err != nil {
+				return err
+			}
+			if paid > 0 {
+				return ErrCheckoutAlreadyPaid
+			}
+
+			paidAt := verification.PaidAt
+			if paidAt.IsZero() {
+				paidAt = time.Now().UTC()
+			}
+			err = tx.Model(&payment).Updates(map[string]interface{}{
+				"status":  constant.Success,
+				"paid_at": paidAt,
+			}).Error
+			if err != nil {
+				return err
+			}
+			payment.Status, payment.PaidAt = constant.Success, paidAt
+
+			closed := []string{constant.Cancelled, constant.Rejected}
+			err = tx.Model(&models.Order{}).Where("checkout_reference = ? AND status NOT IN ?", payment.CheckoutReference, closed).
+				Update("payment_status", constant.Paid).Error
+			if err != nil {
+				return err
+			}
+
+			err = p.PostWalletTransfer(tx, models.WalletTransfer{
+				DebitOwnerID:  constant.ClearingWallet,
+				CreditOwnerID: constant.PlatformWallet,
+				Amount:        payment.Amount,
+				Reference:     payment.Reference,
+				Description:   "payment for checkout " + payment.CheckoutReference,
+			})
+			if err != nil {
+				return err
+			}
+
+			return p.refundClosedOrders(tx, &payment, closed)
+
+		case constant.Failed:
+			payment.Status = constant.Failed
+			return tx.Model(&payment).Update("status", constant.Failed).Error
+		}
+
+		return nil
+	})
+	if err != nil {
+		logger.Logger.Errorf("[ApplyPaymentVerification]error applying payment %s: %s", verification.Reference, err)
+		return nil, err
+	}
+
+	return &payment, nil
+}
+
+// refundClosedOrders refunds the part of a checkout payment that covered orders cancelled or rejected while
+// the payment was in progress. Whatever the payment took beyond the orders still open is refunded against
+// the closed prepaid orders, up to each order's total.
+func (p *PostgresRepository) refundClosedOrders(tx *gorm.DB, payment *models.Payment, closed []string) error {
+	var open int64
+	err := tx.Model(&models.Order{}).Select("COALESCE(SUM(total_amount), 0)").
+		Where("checkout_reference = ? AND payment_terms = ? AND status NOT IN ?", payment.CheckoutReference, constant.Prepayment, closed).
+		Scan(&open).Error
+	if err != nil {
+		return err
+	}
+
+	excess := payment.Amount - utils.ToKobo(open)
+	if excess <= 0 {
+		return nil
+	}
+
+	var orders []models.Order
+	err = tx.Where("checkout_reference = ? AND payment_terms = ? AND status IN ?", payment.CheckoutReference, constant.Prepayment, closed).
+		Order("created_at asc").Find(&orders).Error
+	if err != nil {
+		return err
+	}
+
+	for i := range orders {
+		if excess <= 0 {
+			break
+		}
+		amount := min(utils.ToKobo(orders[i].TotalAmount), excess)
+		if _, err = p.createRefund(tx, &orders[i], amount, constant.Provider, "order was closed before its payment completed"); err != nil {
+			return err
+		}
+		excess -= amount
+	}
+	return nil
+}
@@ -92,7 +92,11 @@ func (p *PostgresRepository) Close() error {
 }
 
 func (p *PostgresRepository) Migrate() error {
-	return p.db.AutoMigrate(&models.User{}, &models.Product{}, &models.ProductUpload{}, &models.Order{}, &models.Cart{})
+	err := p.db.AutoMigrate(&models.User{}, &models.Product{}, &models.ProductUpload{}, &models.Order{}, &models.OrderItem{}, &models.OrderStatusHistory{}, &models.Cart{}, &models.CartItem{}, &models.Settlement{}, &models.Wallet{}, &models.WalletTransaction{}, &models.LedgerEntry{}, &models.Payment{}, &models.OrderDocument{}, &models.Shipment{}, &models.ShipmentItem{}, &models.Refund{}, &models.Rfq{}, &models.Quote{}, &models.QuoteOffer{}, &models.PriceTier{}, &models.ProductVariant{}, &models.StockMovement{}, &models.Notification{}, &models.ProductImport{}, &models.Category{}, &models.Review{})
+	if err != nil {
+		return err
+	}
+	return p.migrateProductSearch()
 }
 
 func (p *PostgresRepository) Ping() error {
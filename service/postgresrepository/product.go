@@ -5,14 +5,41 @@ import (
 	"bambamload/logger"
 	"bambamload/models"
 	"errors"
+	"strings"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
-// CreateProduct
+// CreateProduct saves a new product, bringing its opening stock in through the stock ledger as a receipt
 func (p *PostgresRepository) CreateProduct(req *models.Product) error {
-	return p.db.Create(req).Error
+	return p.db.Transaction(func(tx *gorm.DB) error {
+		stock := req.CurrentStockQuantity
+		variantStock := make([]int64, len(req.Variants))
+		req.CurrentStockQuantity = 0
+		for i := range req.Variants {
+			variantStock[i] = req.Variants[i].CurrentStockQuantity
+			req.Variants[i].CurrentStockQuantity = 0
+		}
+
+		if err := tx.Create(req).Error; err != nil {
+			return err
+		}
+
+		if !req.HasVariants() {
+			req.CurrentStockQuantity = stock
+			return p.receiveInitialStock(tx, req.ID, "", stock, req.SupplierID)
+		}
+
+		for i := range req.Variants {
+			if err := p.receiveInitialStock(tx, req.ID, req.Variants[i].ID, variantStock[i], req.SupplierID); err != nil {
+				return err
+			}
+			req.Variants[i].CurrentStockQuantity = variantStock[i]
+		}
+		req.CurrentStockQuantity = stock
+		return nil
+	})
 }
 
 // GetProduct fetches a product by any identifier provided
@@ -24,7 +51,12 @@ func (p *PostgresRepository) GetProduct(id, identifier string) (*models.Product,
 
 	switch identifier {
 	case constant.ID:
-		err = p.db.Preload(clause.Associations).Where("id = ?", id).First(&product).Error
+		err = p.db.Preload(clause.Associations).Preload("PriceTiers", func(db *gorm.DB) *gorm.DB {
+			return db.Order("min_quantity asc")
+		}).Where("id = ?", id).First(&product).Error
+		if err == nil {
+			product.SetVariantPriceRange()
+		}
 
 	default:
 		return nil, errors.New("identifier is not valid")
@@ -38,6 +70,21 @@ func (p *PostgresRepository) GetProduct(id, identifier string) (*models.Product,
 	return product, nil
 }
 
+// GetListedProduct fetches a product buyers can see, reporting gorm.ErrRecordNotFound for any other product
+func (p *PostgresRepository) GetListedProduct(id string) (*models.Product, error) {
+	var listed int64
+
+	err := filterProducts(p.db.Model(&models.Product{}), models.ProductFilter{ListedOnly: true}).Where("products.id = ?", id).Count(&listed).Error
+	if err != nil {
+		logger.Logger.Errorf("[GetListedProduct]error checking product %s: %s", id, err)
+		return nil, err
+	}
+	if listed == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return p.GetProduct(id, constant.ID)
+}
+
 func (p *PostgresRepository) UpdateProduct(id, identifier string, updates map[string]interface{}) error {
 	var err error
 	switch identifier {
@@ -54,42 +101,129 @@ func (p *PostgresRepository) UpdateProduct(id, identifier string, updates map[st
 	return nil
 }
 
-func (p *PostgresRepository) GetProducts(pm *models.PaginationMetadata, status, searchText, productType string) ([]models.Product, *models.PaginationMetadata, error) {
+// productSortOrders are the orderings a product list can be sorted by. Popularity is the number of units ordered.
+var productSortOrders = map[string]string{
+	constant.Newest:    "products.created_at desc",
+	constant.PriceAsc:  "products.base_unit_price asc",
+	constant.PriceDesc: "products.base_unit_price desc",
+	constant.Rating:    "products.rating desc",
+	constant.Popularity: `(SELECT COALESCE(SUM(oi.quantity - oi.cancelled_quantity), 0) FROM order_items oi
+		WHERE oi.product_id = products.id::text) desc`,
+}
 
-	var (
-		products []models.Product
-		query    *gorm.DB
-	)
+// GetProducts lists the products matching a filter, closest matches first when searching and newest first otherwise
+func (p *PostgresRepository) GetProducts(pm *models.PaginationMetadata, filter models.ProductFilter) ([]models.Product, *models.PaginationMetadata, error) {
 
-	if pm.SupplierID == "" {
-		query = p.db.Model(&models.Product{}).Order("created_at desc")
-	} else {
-		query = p.db.Model(&models.Product{}).Where("supplier_id = ?", pm.SupplierID).Order("created_at desc")
-	}
+	var products []models.Product
 
-	if searchText != "" {
-		search := "%" + searchText + "%"
-		query = query.Where(`
-			name ILIKE ?
-			OR type ILIKE ?
-			OR category ILIKE ?
-		`, search, search, search)
+	query := filterProducts(p.db.Model(&models.Product{}), filter)
+	if filter.Sort == "" && filter.SearchText != "" {
+		query = orderBySearchRank(query, filter.SearchText)
+	} else if order, ok := productSortOrders[filter.Sort]; ok {
+		query = query.Order(order)
 	}
+	query = query.Order("created_at desc")
 
-	if status != "" {
-		query = query.Where("status = ?", status)
+	err := query.Scopes(Paginator(pm, &models.Product{}, query)).Preload("PriceTiers", func(db *gorm.DB) *gorm.DB {
+		return db.Order("min_quantity asc")
+	}).Preload("Variants").Find(&products).Error
+	if err != nil {
+		return nil, pm, err
 	}
 
-	if productType != "" {
-		query = query.Where("type = ?", productType)
+	for i := range products {
+		products[i].SetVariantPriceRange()
 	}
 
-	err := query.Scopes(Paginator(pm, &models.Product{}, query)).Find(&products).Error
+	return products, pm, nil
+}
+
+// StreamProductExport runs the catalog export query and hands each row to write as it is read, so large
+// catalogs are never held in memory. It stops at the first error write returns.
+func (p *PostgresRepository) StreamProductExport(filter models.ProductFilter, write func(models.ProductExportRow) error) error {
+	query := p.db.Table("products").
+		Select(`products.id, products.name, products.category, products.type, products.description, products.base_unit_price,
+			products.unit, products.minimum_order_quantity, products.payment_terms, products.payment_methods,
+			products.current_stock_quantity, products.low_stock_alert_level, products.fulfilment_type,
+			products.estimated_delivery_time, products.status, products.approval_status, products.created_at,
+			products.supplier_id, users.business_name AS supplier_business_name, users.name AS supplier_name,
+			users.email AS supplier_email, users.phone_number AS supplier_phone_number, users.status AS supplier_status`).
+		Joins("LEFT JOIN users ON users.id::text = products.supplier_id")
+
+	rows, err := filterProducts(query, filter).Order("products.created_at desc").Rows()
 	if err != nil {
-		return nil, pm, err
+		logger.Logger.Errorf("[StreamProductExport]error querying products: %s", err)
+		return err
 	}
+	defer rows.Close()
 
-	return products, pm, nil
+	for rows.Next() {
+		var row models.ProductExportRow
+		if err = p.db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err = write(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// filterProducts applies the product list filters. Columns are qualified so the filters also work on joins.
+func filterProducts(query *gorm.DB, filter models.ProductFilter) *gorm.DB {
+	if filter.SupplierID != "" {
+		query = query.Where("products.supplier_id = ?", filter.SupplierID)
+	}
+
+	if filter.SearchText != "" {
+		query = query.Where(productSearchMatch, filter.SearchText, filter.SearchText)
+	}
+
+	if filter.Status != "" {
+		query = query.Where("products.status = ?", filter.Status)
+	}
+
+	if filter.ListedOnly {
+		query = query.Where("products.approval_status = ? AND products.status = ?", constant.Approved, constant.Active).
+			Where("EXISTS (SELECT 1 FROM users u WHERE u.id::text = products.supplier_id AND u.status = ? AND u.is_active AND NOT u.is_blocked)", constant.Approved)
+	}
+
+	if filter.Type != "" {
+		query = query.Where("products.type = ?", filter.Type)
+	}
+
+	if filter.MinPrice > 0 {
+		query = query.Where("products.base_unit_price >= ?", filter.MinPrice)
+	}
+
+	if filter.MaxPrice > 0 {
+		query = query.Where("products.base_unit_price <= ?", filter.MaxPrice)
+	}
+
+	if filter.MaxMinimumOrderQuantity > 0 {
+		query = query.Where("products.minimum_order_quantity <= ?", filter.MaxMinimumOrderQuantity)
+	}
+
+	if filter.FulfilmentType != "" {
+		query = query.Where("products.fulfilment_type IN ?", []string{filter.FulfilmentType, constant.Both})
+	}
+
+	if filter.PaymentTerms != "" {
+		query = query.Where("products.payment_terms = ?", filter.PaymentTerms)
+	}
+
+	if filter.SupplierState != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM users u WHERE u.id::text = products.supplier_id AND LOWER(u.state) = LOWER(TRIM(?)))", filter.SupplierState)
+	}
+
+	if filter.SupplierRegion != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM users u WHERE u.id::text = products.supplier_id AND u.regions_served ILIKE ?)", "%"+strings.TrimSpace(filter.SupplierRegion)+"%")
+	}
+
+	if filter.InStockOnly {
+		query = query.Where("products.current_stock_quantity > 0")
+	}
+	return query
 }
 
 func (p *PostgresRepository) BatchInsertProductUploads(uploads []models.ProductUpload) error {
@@ -123,10 +257,22 @@ func (p *PostgresRepository) GetProductStats() (*models.ProductStats, error) {
 func (p *PostgresRepository) GetSupplierProductStats(supplierID string) (*models.SupplierProductStats, error) {
 	var stats models.SupplierProductStats
 
+	//stock levels are counted per active variant for products sold by variant, and per product otherwise
 	err := p.db.Raw(`
+		WITH stock_items AS (
+			SELECT p.current_stock_quantity, p.low_stock_alert_level
+			FROM products p
+			WHERE p.supplier_id = $1
+			AND NOT EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = p.id::text)
+			UNION ALL
+			SELECT v.current_stock_quantity, v.low_stock_alert_level
+			FROM product_variants v
+			JOIN products p ON p.id::text = v.product_id
+			WHERE p.supplier_id = $1 AND v.status = 'active'
+		)
 		SELECT
-			COUNT(*)::INTEGER AS total_products,
-			COALESCE(SUM(CASE WHEN status = 'active' THEN 1 ELSE 0 END)::INTEGER, 0) AS active_products,
+			(SELECT COUNT(*) FROM products WHERE supplier_id = $1)::INTEGER AS total_products,
+			(SELECT COUNT(*) FROM products WHERE supplier_id = $1 AND status = 'active')::INTEGER AS active_products,
 			COALESCE(SUM(
 				CASE 
 					WHEN current_stock_quantity > 0 
@@ -142,8 +288,7 @@ func (p *PostgresRepository) GetSupplierProductStats(supplierID string) (*models
 					ELSE 0 
 				END
 			)::INTEGER, 0) AS out_of_stock_items
-		FROM products
-		WHERE supplier_id = $1
+		FROM stock_items
 	`, supplierID).Scan(&stats).Error
 
 	if err != nil {
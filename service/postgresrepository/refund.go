@@ -0,0 +1,167 @@
+package postgresrepository
+
+import (
+	"bambamload/constant"
+	"bambamload/logger"
+	"bambamload/models"
+	"bambamload/utils"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// createRefund records a refund of amount kobo against an order's payment. Wallet refunds credit the buyer's
+// wallet from the platform wallet straight away; provider refunds are left pending until the provider pays them out.
+func (p *PostgresRepository) createRefund(tx *gorm.DB, order *models.Order, amount int64, method, reason string) (*models.Refund, error) {
+	var payment models.Payment
+	err := tx.Where("checkout_reference = ? AND status = ?", order.CheckoutReference, constant.Success).First(&payment).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	refund := &models.Refund{
+		Reference:        utils.GenerateReference("RFD"),
+		OrderID:          order.ID,
+		BuyerID:          order.BuyerID,
+		PaymentReference: payment.Reference,
+		Amount:           amount,
+		Method:           method,
+		Status:           constant.Pending,
+		Reason:           reason,
+	}
+	if method == constant.Wallet {
+		refund.Status = constant.Success
+	}
+
+	if err = tx.Create(refund).Error; err != nil {
+		return nil, err
+	}
+
+	if method != constant.Wallet {
+		return refund, nil
+	}
+
+	err = p.PostWalletTransfer(tx, models.WalletTransfer{
+		DebitOwnerID:  constant.PlatformWallet,
+		CreditOwnerID: order.BuyerID,
+		Amount:        amount,
+		Reference:     refund.Reference,
+		Description:   "refund for order " + order.Reference,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return refund, nil
+}
+
+func (p *PostgresRepository) GetRefund(id string) (*models.Refund, error) {
+	var refund *models.Refund
+
+	err := p.db.Preload("Order").Where("id = ?", id).First(&refund).Error
+	if err != nil {
+		logger.Logger.Errorf("error getting refund %s: %s", id, err)
+		return nil, err
+	}
+	return refund, nil
+}
+
+func (p *PostgresRepository) GetRefunds(pm *models.PaginationMetadata, filter models.RefundFilter) ([]models.Refund, *models.PaginationMetadata, error) {
+	var refunds []models.Refund
+
+	query := p.db.Model(&models.Refund{}).Order("created_at desc")
+
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	if filter.Method != "" {
+		query = query.Where("method = ?", filter.Method)
+	}
+
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+
+	err := query.Scopes(Paginator(pm, &models.Refund{}, query)).Preload("Order").Find(&refunds).Error
+	if err != nil {
+		return nil, pm, err
+	}
+
+	return refunds, pm, nil
+}
+
+// GetPendingPaymentRefunds lists the provider refunds against a payment that have not been sent yet
+func (p *PostgresRepository) GetPendingPaymentRefunds(paymentReference string) ([]models.Refund, error) {
+	var refunds []models.Refund
+
+	err := p.db.Where("payment_reference = ? AND method = ? AND status = ?", paymentReference, constant.Provider, constant.Pending).
+		Order("created_at asc").Find(&refunds).Error
+	if err != nil {
+		logger.Logger.Errorf("[GetPendingPaymentRefunds]error getting refunds for payment %s: %s", paymentReference, err)
+		return nil, err
+	}
+	return refunds, nil
+}
+
+// ClaimRefund marks a pending or failed provider refund as processing so it is only sent to the provider once.
+// It reports false when another request already holds the refund or it has been paid out.
+func (p *PostgresRepository) ClaimRefund(id string) (bool, error) {
+	res := p.db.Model(&models.Refund{}).
+		Where("id = ? AND method = ? AND status IN ?", id, constant.Provider, []string{constant.Pending, constant.Failed}).
+		Updates(map[string]interface{}{"status": constant.Processing, "claimed_at": time.Now().UTC()})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+// ResetStaleRefund marks a refund that has been processing since before claimedBefore as failed, so a refund
+// whose provider call never came back can be retried. It reports false when the refund is not stuck.
+func (p *PostgresRepository) ResetStaleRefund(id string, claimedBefore time.Time, failureReason string) (bool, error) {
+	res := p.db.Model(&models.Refund{}).
+		Where("id = ? AND status = ? AND (claimed_at IS NULL OR claimed_at < ?)", id, constant.Processing, claimedBefore).
+		Updates(map[string]interface{}{"status": constant.Failed, "failure_reason": failureReason})
+	if res.Error != nil {
+		logger.Logger.Errorf("[ResetStaleRefund]error resetting refund %s: %s", id, res.Error)
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+// CompleteRefund records the provider's answer for a refund. A paid out refund leaves the platform wallet
+// through the clearing wallet, mirroring how the payment came in.
+func (p *PostgresRepository) CompleteRefund(refund *models.Refund, result *models.PaymentRefund, failureReason string) error {
+	updates := map[string]interface{}{"status": constant.Failed, "failure_reason": failureReason}
+	if result != nil {
+		updates = map[string]interface{}{"status": constant.Success, "provider_reference": result.RefundReference, "failure_reason": ""}
+	}
+
+	err := p.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Refund{}).Where("id = ?", refund.ID).Updates(updates).Error; err != nil {
+			return err
+		}
+		if result == nil {
+			return nil
+		}
+
+		return p.PostWalletTransfer(tx, models.WalletTransfer{
+			DebitOwnerID:  constant.PlatformWallet,
+			CreditOwnerID: constant.ClearingWallet,
+			Amount:        refund.Amount,
+			Reference:     refund.Reference,
+			Description:   "refund paid out for payment " + refund.PaymentReference,
+		})
+	})
+	if err != nil {
+		logger.Logger.Errorf("[CompleteRefund]error completing refund %s: %s", refund.Reference, err)
+		return err
+	}
+
+	refund.Status = updates["status"].(string)
+	return nil
+}
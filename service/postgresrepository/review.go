@@ -0,0 +1,136 @@
+package postgresrepository
+
+import (
+	"bambamload/constant"
+	"bambamload/logger"
+	"bambamload/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// HasCompletedOrderFor reports whether an order is the buyer's, is completed and includes the product
+func (p *PostgresRepository) HasCompletedOrderFor(buyerID, orderID, productID string) (bool, error) {
+	var count int64
+
+	err := p.db.Model(&models.OrderItem{}).
+		Joins("JOIN orders o ON o.id::text = order_items.order_id").
+		Where("o.id::text = ? AND o.buyer_id = ? AND o.status = ? AND order_items.product_id = ?", orderID, buyerID, constant.Completed, productID).
+		Count(&count).Error
+	if err != nil {
+		logger.Logger.Errorf("[HasCompletedOrderFor]error checking order %s: %s", orderID, err)
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// ReviewExists reports whether a product has already been reviewed for an order
+func (p *PostgresRepository) ReviewExists(orderID, productID string) (bool, error) {
+	var count int64
+
+	if err := p.db.Model(&models.Review{}).Where("order_id = ? AND product_id = ?", orderID, productID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// CreateReview saves a review, adding it to its product's rating when it is published
+func (p *PostgresRepository) CreateReview(review *models.Review) error {
+	err := p.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(review).Error; err != nil {
+			return err
+		}
+		if review.Status != constant.Published {
+			return nil
+		}
+		return applyReviewRating(tx, review.ProductID, int64(review.Rating), 1)
+	})
+	if err != nil {
+		logger.Logger.Errorf("[CreateReview]error creating review for product %s: %s", review.ProductID, err)
+		return err
+	}
+	return nil
+}
+
+func (p *PostgresRepository) GetReview(id string) (*models.Review, error) {
+	var review *models.Review
+
+	if err := p.db.Where("id = ?", id).First(&review).Error; err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// GetReviews lists reviews newest first, narrowed to a product, the products of a supplier or a status
+func (p *PostgresRepository) GetReviews(pm *models.PaginationMetadata, filter models.ReviewFilter) ([]models.Review, *models.PaginationMetadata, error) {
+	var reviews []models.Review
+
+	query := p.db.Model(&models.Review{})
+	if filter.ProductID != "" {
+		query = query.Where("product_id = ?", filter.ProductID)
+	}
+	if filter.SupplierID != "" {
+		query = query.Where("product_id IN (SELECT id::text FROM products WHERE supplier_id = ?)", filter.SupplierID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	query = query.Order("created_at desc")
+
+	err := query.Scopes(Paginator(pm, &models.Review{}, query)).Find(&reviews).Error
+	if err != nil {
+		logger.Logger.Errorf("[GetReviews]error getting reviews: %s", err)
+		return nil, pm, err
+	}
+	return reviews, pm, nil
+}
+
+// ReplyToReview saves the supplier's reply to a review. It reports false when the review already has a reply.
+func (p *PostgresRepository) ReplyToReview(id, reply string) (bool, error) {
+	result := p.db.Model(&models.Review{}).Where("id = ? AND COALESCE(reply, '') = ''", id).
+		Updates(map[string]interface{}{"reply": reply, "replied_at": time.Now().UTC()})
+	if result.Error != nil {
+		logger.Logger.Errorf("[ReplyToReview]error replying to review %s: %s", id, result.Error)
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// SetReviewStatus publishes or hides a review, moving its rating onto or off its product. It reports false
+// when the review is already in that status.
+func (p *PostgresRepository) SetReviewStatus(review *models.Review, status, moderatedBy, reason string) (bool, error) {
+	changed := false
+
+	err := p.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Review{}).Where("id = ? AND status <> ?", review.ID, status).
+			Updates(map[string]interface{}{"status": status, "moderated_by": moderatedBy, "moderation_reason": reason})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		changed = true
+		if status == constant.Published {
+			return applyReviewRating(tx, review.ProductID, int64(review.Rating), 1)
+		}
+		return applyReviewRating(tx, review.ProductID, -int64(review.Rating), -1)
+	})
+	if err != nil {
+		logger.Logger.Errorf("[SetReviewStatus]error setting review %s to %s: %s", review.ID, status, err)
+		return false, err
+	}
+	return changed, nil
+}
+
+// applyReviewRating adds a review's stars to, or takes them from, its product's running total and recomputes
+// the average from the total rather than rescanning every review
+func applyReviewRating(tx *gorm.DB, productID string, stars, count int64) error {
+	return tx.Model(&models.Product{}).Where("id = ?", productID).Updates(map[string]interface{}{
+		"rating_total": gorm.Expr("rating_total + ?", stars),
+		"review_count": gorm.Expr("review_count + ?", count),
+		"rating": gorm.Expr("CASE WHEN review_count + ? > 0 THEN ROUND((rating_total + ?)::numeric / (review_count + ?), 2) ELSE 0 END",
+			count, stars, count),
+	}).Error
+}
@@ -0,0 +1,248 @@
+package postgresrepository
+
+import (
+	"bambamload/constant"
+	"bambamload/logger"
+	"bambamload/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrQuoteChanged = errors.New("quote has changed, please refresh and try again")
+
+func (p *PostgresRepository) CreateRfq(rfq *models.Rfq) error {
+	if err := p.db.Create(rfq).Error; err != nil {
+		logger.Logger.Errorf("[CreateRfq]error creating rfq for buyer %s: %s", rfq.BuyerID, err)
+		return err
+	}
+	return nil
+}
+
+// GetRfq fetches an RFQ with its quotes and their negotiation history
+func (p *PostgresRepository) GetRfq(id string) (*models.Rfq, error) {
+	var rfq *models.Rfq
+
+	err := p.db.Preload("Buyer", selectPublicUser).Preload("Product").
+		Preload("Quotes", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at asc")
+		}).
+		Preload("Quotes.Product").Preload("Quotes.Supplier", selectPublicUser).
+		Preload("Quotes.Offers", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at asc")
+		}).
+		Where("id = ?", id).First(&rfq).Error
+	if err != nil {
+		logger.Logger.Errorf("error getting rfq %s: %s", id, err)
+		return nil, err
+	}
+	return rfq, nil
+}
+
+func (p *PostgresRepository) GetRfqs(pm *models.PaginationMetadata, filter models.RfqFilter) ([]models.Rfq, *models.PaginationMetadata, error) {
+	var rfqs []models.Rfq
+
+	query := p.db.Model(&models.Rfq{}).Order("created_at desc")
+
+	if filter.BuyerID != "" {
+		query = query.Where("buyer_id = ?", filter.BuyerID)
+	}
+
+	//a supplier sees requests for its own products, or for a category it sells in
+	if filter.SupplierID != "" {
+		supplierProducts := p.db.Model(&models.Product{}).Select("CAST(id AS text)").Where("supplier_id = ?", filter.SupplierID)
+		supplierCategories := p.db.Model(&models.Product{}).Select("category").
+			Where("supplier_id = ? AND approval_status = ? AND status = ?", filter.SupplierID, constant.Approved, constant.Active)
+
+		query = query.Where("product_id IN (?) OR (COALESCE(product_id, '') = '' AND category IN (?))", supplierProducts, supplierCategories)
+	}
+
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	err := query.Scopes(Paginator(pm, &models.Rfq{}, query)).Preload("Product").Find(&rfqs).Error
+	if err != nil {
+		return nil, pm, err
+	}
+
+	return rfqs, pm, nil
+}
+
+// CancelRfq closes an open RFQ and declines its pending quotes
+func (p *PostgresRepository) CancelRfq(rfq *models.Rfq) error {
+	err := p.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.Rfq{}).Where("id = ? AND status = ?", rfq.ID, constant.Open).Update("status", constant.Cancelled)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errors.New("rfq is no longer open")
+		}
+
+		return tx.Model(&models.Quote{}).Where("rfq_id = ? AND status = ?", rfq.ID, constant.Pending).
+			Update("status", constant.Rejected).Error
+	})
+	if err != nil {
+		logger.Logger.Errorf("[CancelRfq]error cancelling rfq %s: %s", rfq.ID, err)
+		return err
+	}
+
+	rfq.Status = constant.Cancelled
+	return nil
+}
+
+// CreateQuote saves a supplier's quote together with its opening offer
+func (p *PostgresRepository) CreateQuote(quote *models.Quote) error {
+	if err := p.db.Create(quote).Error; err != nil {
+		logger.Logger.Errorf("[CreateQuote]error creating quote on rfq %s: %s", quote.RfqID, err)
+		return err
+	}
+	return nil
+}
+
+func (p *PostgresRepository) GetQuote(id string) (*models.Quote, error) {
+	var quote *models.Quote
+
+	err := p.db.Preload("Rfq").Preload("Rfq.Buyer", selectPublicUser).Preload("Product").Preload("Product.Supplier", selectPublicUser).
+		Preload("Product.Variants").Preload("Supplier", selectPublicUser).
+		Preload("Offers", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at asc")
+		}).
+		Where("id = ?", id).First(&quote).Error
+	if err != nil {
+		logger.Logger.Errorf("error getting quote %s: %s", id, err)
+		return nil, err
+	}
+	return quote, nil
+}
+
+func (p *PostgresRepository) GetSupplierQuotes(pm *models.PaginationMetadata, supplierID, status string) ([]models.Quote, *models.PaginationMetadata, error) {
+	var quotes []models.Quote
+
+	query := p.db.Model(&models.Quote{}).Where("supplier_id = ?", supplierID).Order("updated_at desc")
+
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	err := query.Scopes(Paginator(pm, &models.Quote{}, query)).Preload("Rfq").Preload("Product").Find(&quotes).Error
+	if err != nil {
+		return nil, pm, err
+	}
+
+	return quotes, pm, nil
+}
+
+// CounterQuote replaces the terms of a pending quote with a counter-offer and hands it to the other side
+func (p *PostgresRepository) CounterQuote(quote *models.Quote, offer *models.QuoteOffer, awaitingRole string) error {
+	err := p.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.Quote{}).
+			Where("id = ? AND status = ? AND awaiting_role = ?", quote.ID, constant.Pending, quote.AwaitingRole).
+			Updates(map[string]interface{}{
+				"unit_price":    offer.UnitPrice,
+				"quantity":      offer.Quantity,
+				"expires_at":    offer.ExpiresAt,
+				"awaiting_role": awaitingRole,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrQuoteChanged
+		}
+
+		return tx.Create(offer).Error
+	})
+	if err != nil {
+		logger.Logger.Errorf("[CounterQuote]error countering quote %s: %s", quote.ID, err)
+		return err
+	}
+
+	quote.UnitPrice, quote.Quantity, quote.ExpiresAt, quote.AwaitingRole = offer.UnitPrice, offer.Quantity, offer.ExpiresAt, awaitingRole
+	return nil
+}
+
+// AcceptQuote places the order agreed on a quote. The quote and its RFQ are marked accepted and the other
+// pending quotes on the RFQ are declined, all in the same transaction as the order.
+func (p *PostgresRepository) AcceptQuote(quote *models.Quote, order *models.Order) error {
+	err := p.db.Transaction(func(tx *gorm.DB) error {
+		var rfq models.Rfq
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", quote.RfqID).First(&rfq).Error
+		if err != nil {
+			return err
+		}
+		if rfq.Status != constant.Open {
+			return ErrQuoteChanged
+		}
+
+		//the order keeps the supplier's commission rate at the time it was placed
+		err = tx.Model(&models.User{}).Select("commission_rate").Where("id = ?", order.SupplierID).Scan(&order.CommissionRate).Error
+		if err != nil {
+			return err
+		}
+
+		orders := []models.Order{*order}
+		if err = p.placeOrders(tx, orders); err != nil {
+			return err
+		}
+		*order = orders[0]
+
+		res := tx.Model(&models.Quote{}).
+			Where("id = ? AND status = ? AND awaiting_role = ? AND expires_at > ?", quote.ID, constant.Pending, quote.AwaitingRole, time.Now().UTC()).
+			Updates(map[string]interface{}{"status": constant.Accepted, "order_id": order.ID})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrQuoteChanged
+		}
+
+		err = tx.Model(&models.Quote{}).Where("rfq_id = ? AND id <> ? AND status = ?", rfq.ID, quote.ID, constant.Pending).
+			Update("status", constant.Rejected).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&rfq).Update("status", constant.Accepted).Error
+	})
+	if err != nil {
+		logger.Logger.Errorf("[AcceptQuote]error accepting quote %s: %s", quote.ID, err)
+		return err
+	}
+
+	quote.Status, quote.OrderID = constant.Accepted, order.ID
+	return nil
+}
+
+// RejectQuote declines a pending quote on behalf of the side it is waiting on
+func (p *PostgresRepository) RejectQuote(quote *models.Quote) error {
+	res := p.db.Model(&models.Quote{}).
+		Where("id = ? AND status = ? AND awaiting_role = ?", quote.ID, constant.Pending, quote.AwaitingRole).
+		Update("status", constant.Rejected)
+	if res.Error != nil {
+		logger.Logger.Errorf("[RejectQuote]error rejecting quote %s: %s", quote.ID, res.Error)
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrQuoteChanged
+	}
+
+	quote.Status = constant.Rejected
+	return nil
+}
+
+// SupplierSellsInCategory reports whether a supplier has an approved, active product in a category
+func (p *PostgresRepository) SupplierSellsInCategory(supplierID, category string) (bool, error) {
+	var count int64
+
+	err := p.db.Model(&models.Product{}).
+		Where("supplier_id = ? AND category = ? AND approval_status = ? AND status = ?", supplierID, category, constant.Approved, constant.Active).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,101 @@
+package postgresrepository
+
+import (
+	"bambamload/logger"
+	"bambamload/models"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Product search matches a weighted tsvector of the name (A), category and type (B) and description (C),
+// and tolerates typos in the name through trigram word similarity. The vector is a generated column so
+// it can never go stale, and both halves of the search are indexed.
+var productSearchMigrations = []string{
+	`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+	`ALTER TABLE products ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+		setweight(to_tsvector('english', COALESCE(name, '')), 'A') ||
+		setweight(to_tsvector('english', COALESCE(category, '') || ' ' || COALESCE(type, '')), 'B') ||
+		setweight(to_tsvector('english', COALESCE(description, '')), 'C')
+	) STORED`,
+	`CREATE INDEX IF NOT EXISTS idx_products_search_vector ON products USING GIN (search_vector)`,
+	`CREATE INDEX IF NOT EXISTS idx_products_name_trgm ON products USING GIN (name gin_trgm_ops)`,
+}
+
+// productSearchMatch matches products to websearch syntax ("quoted phrases", or, -exclusions) or, failing
+// that, to a name that is a close trigram match for the search text
+const productSearchMatch = `(products.search_vector @@ websearch_to_tsquery('english', ?) OR ? <% products.name)`
+
+// productSearchRank scores a match, the weighted text rank plus how closely the name matches
+const productSearchRank = `ts_rank(products.search_vector, websearch_to_tsquery('english', ?)) + word_similarity(?, products.name)`
+
+// productPriceBands are the base unit price bands, in naira, that search results are counted in
+var productPriceBands = []struct {
+	Label string
+	Min   int64
+	Max   int64 //0 means no upper limit
+}{
+	{"under_10000", 0, 10000},
+	{"10000_to_50000", 10000, 50000},
+	{"50000_to_100000", 50000, 100000},
+	{"100000_to_500000", 100000, 500000},
+	{"500000_and_above", 500000, 0},
+}
+
+func (p *PostgresRepository) migrateProductSearch() error {
+	for _, statement := range productSearchMigrations {
+		if err := p.db.Exec(statement).Error; err != nil {
+			logger.Logger.Errorf("[migrateProductSearch]error running %q: %s", statement, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// orderBySearchRank puts the closest matches to the search text first
+func orderBySearchRank(query *gorm.DB, searchText string) *gorm.DB {
+	return query.Order(clause.OrderBy{Expression: clause.Expr{
+		SQL:  productSearchRank + " DESC",
+		Vars: []interface{}{searchText, searchText},
+	}})
+}
+
+// GetProductFacets counts the products matching a filter by category, type, fulfilment type and price band
+func (p *PostgresRepository) GetProductFacets(filter models.ProductFilter) (*models.ProductFacets, error) {
+	var (
+		facets models.ProductFacets
+		bands  strings.Builder
+	)
+
+	bands.WriteString("CASE")
+	for _, band := range productPriceBands {
+		if band.Max == 0 {
+			fmt.Fprintf(&bands, " WHEN products.base_unit_price >= %d THEN '%s'", band.Min, band.Label)
+			continue
+		}
+		fmt.Fprintf(&bands, " WHEN products.base_unit_price >= %d AND products.base_unit_price < %d THEN '%s'", band.Min, band.Max, band.Label)
+	}
+	bands.WriteString(" END")
+
+	for _, facet := range []struct {
+		column string
+		counts *[]models.FacetCount
+	}{
+		{"products.category", &facets.Categories},
+		{"products.type", &facets.Types},
+		{"products.fulfilment_type", &facets.FulfilmentTypes},
+		{bands.String(), &facets.PriceBands},
+	} {
+		err := filterProducts(p.db.Model(&models.Product{}), filter).
+			Select(fmt.Sprintf("COALESCE(%s, '') AS value, COUNT(*) AS count", facet.column)).
+			Group("value").Order("count desc, value asc").
+			Scan(facet.counts).Error
+		if err != nil {
+			logger.Logger.Errorf("[GetProductFacets]error counting products by %s: %s", facet.column, err)
+			return nil, err
+		}
+	}
+	return &facets, nil
+}
@@ -0,0 +1,81 @@
+package postgresrepository
+
+import (
+	"bambamload/constant"
+	"bambamload/models"
+	"bambamload/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// recordSettlement writes the settlement ledger entry for a completed order.
+// The commission uses the rate captured on the order at checkout, not the supplier's current rate.
+func (p *PostgresRepository) recordSettlement(tx *gorm.DB, order *models.Order) error {
+	commission := utils.CalculateCommission(order.TotalAmount, order.CommissionRate)
+
+	settlement := &models.Settlement{
+		OrderID:          order.ID,
+		OrderReference:   order.Reference,
+		SupplierID:       order.SupplierID,
+		PaymentTerms:     order.PaymentTerms,
+		GrossAmount:      order.TotalAmount,
+		CommissionRate:   order.CommissionRate,
+		CommissionAmount: commission,
+		NetAmount:        order.TotalAmount - commission,
+		Status:           constant.Pending,
+	}
+
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(settlement).Error
+}
+
+func (p *PostgresRepository) settlementsQuery(filter models.SettlementFilter) *gorm.DB {
+	query := p.db.Model(&models.Settlement{})
+
+	if filter.SupplierID != "" {
+		query = query.Where("supplier_id = ?", filter.SupplierID)
+	}
+
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+
+	return query
+}
+
+func (p *PostgresRepository) GetSettlements(pm *models.PaginationMetadata, filter models.SettlementFilter) ([]models.Settlement, *models.PaginationMetadata, error) {
+	var settlements []models.Settlement
+
+	query := p.settlementsQuery(filter).Order("created_at desc")
+
+	err := query.Scopes(Paginator(pm, &models.Settlement{}, query)).Preload("Supplier").Find(&settlements).Error
+	if err != nil {
+		return nil, pm, err
+	}
+
+	return settlements, pm, nil
+}
+
+func (p *PostgresRepository) GetSettlementSummary(filter models.SettlementFilter) (*models.SettlementSummary, error) {
+	var summary models.SettlementSummary
+
+	err := p.settlementsQuery(filter).Select(`
+		COUNT(*) AS total_orders,
+		COALESCE(SUM(gross_amount), 0) AS gross_amount,
+		COALESCE(SUM(commission_amount), 0) AS commission_amount,
+		COALESCE(SUM(net_amount), 0) AS net_amount
+	`).Scan(&summary).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &summary, nil
+}
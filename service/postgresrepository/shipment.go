@@ -0,0 +1,174 @@
+package postgresrepository
+
+import (
+	"bambamload/constant"
+	"bambamload/logger"
+	"bambamload/models"
+	"bambamload/utils"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrNothingOutstanding = errors.New("nothing is outstanding on this item")
+	ErrQuantityExceeded   = errors.New("quantity is more than what is outstanding")
+)
+
+// SaveShipment records a shipment and moves the shipped quantities on the order items.
+// Each line ships its in-stock units first; anything beyond that fills its backorder. Checkout already took
+// the whole line out of stock, so a backorder shipping only releases the units from the product's backorder.
+// When tx is nil the shipment is saved in its own transaction.
+func (p *PostgresRepository) SaveShipment(tx *gorm.DB, shipment *models.Shipment) error {
+	if tx == nil {
+		return p.db.Transaction(func(tx *gorm.DB) error {
+			return p.SaveShipment(tx, shipment)
+		})
+	}
+
+	for i := range shipment.ShipmentItems {
+		shipmentItem := &shipment.ShipmentItems[i]
+
+		var item models.OrderItem
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND order_id = ?", shipmentItem.OrderItemID, shipment.OrderID).First(&item).Error
+		if err != nil {
+			return err
+		}
+
+		if shipmentItem.Quantity > item.OutstandingQuantity() {
+			return fmt.Errorf("%w: %s", ErrQuantityExceeded, item.ProductName)
+		}
+		shipmentItem.ProductID = item.ProductID
+		shipmentItem.FromBackorder = max(shipmentItem.Quantity-(item.OutstandingQuantity()-item.BackorderedQuantity), 0)
+
+		err = tx.Model(&item).Updates(map[string]interface{}{
+			"shipped_quantity":     gorm.Expr("shipped_quantity + ?", shipmentItem.Quantity),
+			"backordered_quantity": gorm.Expr("backordered_quantity - ?", shipmentItem.FromBackorder),
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		if shipmentItem.FromBackorder == 0 {
+			continue
+		}
+
+		err = tx.Model(&models.Product{}).Where("id = ?", item.ProductID).
+			Update("backordered_quantity", gorm.Expr("backordered_quantity - ?", shipmentItem.FromBackorder)).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Create(shipment).Error; err != nil {
+		logger.Logger.Errorf("[SaveShipment]error saving shipment for order %s: %s", shipment.OrderID, err)
+		return err
+	}
+	return nil
+}
+
+// ResolveUnfilledItems settles the unshipped quantity on order lines. A backorder keeps the quantity open until
+// the supplier can ship it, and counts it on the product as owed. A cancellation takes it off the order total and,
+// when the order has been paid for, refunds its value to the buyer's wallet. Stock is left as is in both cases:
+// checkout already took the units out of stock, and they were not on hand to ship.
+// actions maps an order item id to constant.Backorder or constant.Cancel. It returns the value of the cancelled units.
+func (p *PostgresRepository) ResolveUnfilledItems(order *models.Order, actions map[string]string, reason string) (int64, error) {
+	var cancelled int64
+
+	err := p.db.Transaction(func(tx *gorm.DB) error {
+		var current models.Order
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", order.ID).First(&current).Error
+		if err != nil {
+			return err
+		}
+		if current.Status != order.Status {
+			return ErrOrderStatusChanged
+		}
+
+		for itemID, action := range actions {
+			var item models.OrderItem
+			err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id = ? AND order_id = ?", itemID, order.ID).First(&item).Error
+			if err != nil {
+				return err
+			}
+
+			switch action {
+			case constant.Backorder:
+				quantity := item.OutstandingQuantity() - item.BackorderedQuantity
+				if quantity <= 0 {
+					return fmt.Errorf("%w: %s", ErrNothingOutstanding, item.ProductName)
+				}
+
+				err = tx.Model(&item).Update("backordered_quantity", gorm.Expr("backordered_quantity + ?", quantity)).Error
+				if err != nil {
+					return err
+				}
+				err = tx.Model(&models.Product{}).Where("id = ?", item.ProductID).
+					Update("backordered_quantity", gorm.Expr("backordered_quantity + ?", quantity)).Error
+				if err != nil {
+					return err
+				}
+
+			case constant.Cancel:
+				quantity := item.OutstandingQuantity()
+				if quantity <= 0 {
+					return fmt.Errorf("%w: %s", ErrNothingOutstanding, item.ProductName)
+				}
+
+				err = tx.Model(&item).Updates(map[string]interface{}{
+					"cancelled_quantity":   gorm.Expr("cancelled_quantity + ?", quantity),
+					"backordered_quantity": 0,
+				}).Error
+				if err != nil {
+					return err
+				}
+				if item.BackorderedQuantity > 0 {
+					err = tx.Model(&models.Product{}).Where("id = ?", item.ProductID).
+						Update("backordered_quantity", gorm.Expr("backordered_quantity - ?", item.BackorderedQuantity)).Error
+					if err != nil {
+						return err
+					}
+				}
+				cancelled += quantity * item.UnitPrice
+
+			default:
+				return fmt.Errorf("invalid action %s", action)
+			}
+		}
+
+		if cancelled == 0 {
+			return nil
+		}
+
+		err = tx.Model(&models.Order{}).Where("id = ?", order.ID).Updates(map[string]interface{}{
+			"sub_total":    gorm.Expr("sub_total - ?", cancelled),
+			"total_amount": gorm.Expr("total_amount - ?", cancelled),
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		//stored invoices no longer match the order total
+		if err = tx.Where("order_id = ?", order.ID).Delete(&models.OrderDocument{}).Error; err != nil {
+			return err
+		}
+
+		if current.PaymentStatus != constant.Paid {
+			return nil
+		}
+		_, err = p.createRefund(tx, &current, utils.ToKobo(cancelled), constant.Wallet, reason)
+		return err
+	})
+	if err != nil {
+		logger.Logger.Errorf("[ResolveUnfilledItems]error resolving items on order %s: %s", order.ID, err)
+		return 0, err
+	}
+
+	order.SubTotal -= cancelled
+	order.TotalAmount -= cancelled
+	return cancelled, nil
+}
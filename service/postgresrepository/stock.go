@@ -0,0 +1,232 @@
+package postgresrepository
+
+import (
+	"bambamload/constant"
+	"bambamload/logger"
+	"bambamload/models"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PostStockMovement records a movement in a product's stock ledger and sets the stock it moves, the variant's
+// when one is given and the product's otherwise, to the new running total. The stock row is locked for the
+// duration of the transaction so concurrent movements cannot take it below zero.
+// When tx is nil the movement is posted in its own transaction.
+func (p *PostgresRepository) PostStockMovement(tx *gorm.DB, movement *models.StockMovement) error {
+	if tx == nil {
+		return p.db.Transaction(func(tx *gorm.DB) error {
+			return p.PostStockMovement(tx, movement)
+		})
+	}
+
+	if movement.Quantity == 0 {
+		return errors.New("stock movement quantity cannot be zero")
+	}
+
+	balance, err := p.openStockBalance(tx, movement.ProductID, movement.VariantID, movement.CreatedBy)
+	if err != nil {
+		return err
+	}
+	if balance+movement.Quantity < 0 {
+		return fmt.Errorf("%w: %d in stock", ErrInsufficientStock, balance)
+	}
+
+	movement.BalanceAfter = balance + movement.Quantity
+	if err = tx.Create(movement).Error; err != nil {
+		logger.Logger.Errorf("[PostStockMovement]error recording %s for product %s: %s", movement.Type, movement.ProductID, err)
+		return err
+	}
+
+	if movement.VariantID == "" {
+		return tx.Model(&models.Product{}).Where("id = ?", movement.ProductID).
+			Update("current_stock_quantity", movement.BalanceAfter).Error
+	}
+
+	err = tx.Model(&models.ProductVariant{}).Where("id = ?", movement.VariantID).
+		Update("current_stock_quantity", movement.BalanceAfter).Error
+	if err != nil {
+		return err
+	}
+	return syncVariantStock(tx, movement.ProductID)
+}
+
+// countStock sets stock to a counted level by posting the difference as an adjustment
+func (p *PostgresRepository) countStock(tx *gorm.DB, count *models.StockCount) error {
+	balance, err := p.openStockBalance(tx, count.ProductID, count.VariantID, count.CreatedBy)
+	if err != nil {
+		return err
+	}
+	if count.Quantity == balance {
+		return nil
+	}
+
+	return p.PostStockMovement(tx, &models.StockMovement{
+		ProductID: count.ProductID,
+		VariantID: count.VariantID,
+		Type:      constant.Adjustment,
+		Quantity:  count.Quantity - balance,
+		Reason:    count.Reason,
+		CreatedBy: count.CreatedBy,
+	})
+}
+
+// receiveInitialStock records the stock a new product or variant is listed with as a receipt
+func (p *PostgresRepository) receiveInitialStock(tx *gorm.DB, productID, variantID string, quantity int64, createdBy string) error {
+	if quantity <= 0 {
+		return nil
+	}
+	return p.PostStockMovement(tx, &models.StockMovement{
+		ProductID: productID,
+		VariantID: variantID,
+		Type:      constant.Receipt,
+		Quantity:  quantity,
+		Reason:    "initial stock",
+		CreatedBy: createdBy,
+	})
+}
+
+// openStockBalance locks the stock row a movement applies to and returns its ledger balance.
+// Stock held before the ledger existed is carried in as an opening balance the first time it moves.
+func (p *PostgresRepository) openStockBalance(tx *gorm.DB, productID, variantID, createdBy string) (int64, error) {
+	var (
+		current int64
+		err     error
+	)
+
+	if variantID != "" {
+		var variant models.ProductVariant
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ? AND product_id = ?", variantID, productID).First(&variant).Error
+		current = variant.CurrentStockQuantity
+	} else {
+		var product models.Product
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", productID).First(&product).Error
+		current = product.CurrentStockQuantity
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var ledger struct {
+		Balance   int64
+		Movements int64
+	}
+	err = stockLedger(tx, productID, variantID).Select("COALESCE(SUM(quantity), 0) AS balance, COUNT(*) AS movements").Scan(&ledger).Error
+	if err != nil {
+		return 0, err
+	}
+
+	if ledger.Movements > 0 || current == 0 {
+		return ledger.Balance, nil
+	}
+
+	opening := &models.StockMovement{
+		ProductID:    productID,
+		VariantID:    variantID,
+		Type:         constant.OpeningBalance,
+		Quantity:     current,
+		BalanceAfter: current,
+		Reason:       "stock held before the stock ledger",
+		CreatedBy:    createdBy,
+	}
+	if err = tx.Create(opening).Error; err != nil {
+		return 0, err
+	}
+	return current, nil
+}
+
+// GetStockMovements lists the stock ledger of a product, or of one of its variants, newest first
+func (p *PostgresRepository) GetStockMovements(pm *models.PaginationMetadata, productID, variantID string) ([]models.StockMovement, *models.PaginationMetadata, error) {
+	var movements []models.StockMovement
+
+	query := p.db.Model(&models.StockMovement{}).Where("product_id = ?", productID)
+	if variantID != "" {
+		query = query.Where("variant_id = ?", variantID)
+	}
+	query = query.Order("created_at desc")
+
+	err := query.Scopes(Paginator(pm, &models.StockMovement{}, query)).Find(&movements).Error
+	if err != nil {
+		logger.Logger.Errorf("[GetStockMovements]error getting stock movements for product %s: %s", productID, err)
+		return nil, pm, err
+	}
+	return movements, pm, nil
+}
+
+func stockLedger(tx *gorm.DB, productID, variantID string) *gorm.DB {
+	return tx.Model(&models.StockMovement{}).Where("product_id = ? AND variant_id = ?", productID, variantID)
+}
+
+// GetStockAlerts finds the products, and the active variants of products sold by variant, whose stock state no
+// longer matches the last alert sent for them
+func (p *PostgresRepository) GetStockAlerts() ([]models.StockAlert, error) {
+	var alerts []models.StockAlert
+
+	err := p.db.Raw(`
+		WITH stock_items AS (
+			SELECT p.id::text AS product_id, '' AS variant_id, p.supplier_id, p.name AS product_name, '' AS variant_name,
+				p.current_stock_quantity AS stock, p.low_stock_alert_level AS alert_level, p.stock_alert_state AS previous_state
+			FROM products p
+			WHERE p.status <> $1
+			AND NOT EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = p.id::text)
+			UNION ALL
+			SELECT v.product_id, v.id::text, p.supplier_id, p.name, v.name,
+				v.current_stock_quantity, v.low_stock_alert_level, v.stock_alert_state
+			FROM product_variants v
+			JOIN products p ON p.id::text = v.product_id
+			WHERE p.status <> $1 AND v.status = $2
+		),
+		states AS (
+			SELECT *,
+				CASE
+					WHEN stock <= 0 THEN $3
+					WHEN stock <= alert_level THEN $4
+					ELSE ''
+				END AS state
+			FROM stock_items
+		)
+		SELECT s.*, u.business_name AS supplier_name, u.email AS supplier_email
+		FROM states s
+		JOIN users u ON u.id::text = s.supplier_id
+		WHERE s.state <> COALESCE(s.previous_state, '')
+	`, constant.Deactivated, constant.Active, constant.OutOfStock, constant.LowStock).Scan(&alerts).Error
+	if err != nil {
+		logger.Logger.Errorf("[GetStockAlerts]error finding stock alerts: %s", err)
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// RecordStockAlert moves a product or variant to the alert's state and saves the notification announcing it.
+// It reports false when another run has already moved the state on, so each crossing is announced once.
+func (p *PostgresRepository) RecordStockAlert(alert models.StockAlert, notification *models.Notification) (bool, error) {
+	recorded := false
+
+	err := p.db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&models.Product{}).Where("id = ?", alert.ProductID)
+		if alert.VariantID != "" {
+			query = tx.Model(&models.ProductVariant{}).Where("id = ?", alert.VariantID)
+		}
+
+		result := query.Where("COALESCE(stock_alert_state, '') = ?", alert.PreviousState).Update("stock_alert_state", alert.State)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		recorded = true
+		if notification == nil {
+			return nil
+		}
+		return tx.Create(notification).Error
+	})
+	if err != nil {
+		logger.Logger.Errorf("[RecordStockAlert]error recording %s alert for product %s: %s", alert.State, alert.ProductID, err)
+		return false, err
+	}
+	return recorded, nil
+}
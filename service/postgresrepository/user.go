@@ -7,6 +7,7 @@ import (
 	"bambamload/models"
 	"errors"
 
+	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
@@ -104,6 +105,23 @@ func (p *PostgresRepository) UpdateUser(id, identifier string, updates map[strin
 	return nil
 }
 
+// GetCommissionRates reads the commission rate of each supplier, keyed by supplier id
+func (p *PostgresRepository) GetCommissionRates(supplierIDs []string) (map[string]float32, error) {
+	var suppliers []models.User
+
+	err := p.db.Model(&models.User{}).Select("id", "commission_rate").Where("id IN ?", supplierIDs).Find(&suppliers).Error
+	if err != nil {
+		logger.Logger.Errorf("[GetCommissionRates]error getting commission rates: %s", err)
+		return nil, err
+	}
+
+	rates := make(map[string]float32, len(suppliers))
+	for _, supplier := range suppliers {
+		rates[supplier.ID] = supplier.CommissionRate
+	}
+	return rates, nil
+}
+
 func (p *PostgresRepository) AdminDashboardCards() (any, error) {
 
 	var stats models.DashboardStats
@@ -227,9 +245,34 @@ func (p *PostgresRepository) GetSuppliers(pm *models.PaginationMetadata, status,
 	return suppliers, pm, nil
 }
 
-func (p *PostgresRepository) SupplierEditProduct(productID string, updateMap map[string]interface{}) error {
+// SupplierEditProduct updates a product. When priceTiers is not nil it replaces the product's tiers, an empty slice clearing them.
+// A stock count, when given, goes through the stock ledger as an adjustment.
+func (p *PostgresRepository) SupplierEditProduct(productID string, updateMap map[string]interface{}, priceTiers []models.PriceTier, count *models.StockCount) error {
 
-	err := p.db.Model(&models.Product{}).Where("id = ?", productID).Updates(updateMap).Error
+	err := p.db.Transaction(func(tx *gorm.DB) error {
+		if len(updateMap) > 0 {
+			if err := tx.Model(&models.Product{}).Where("id = ?", productID).Updates(updateMap).Error; err != nil {
+				return err
+			}
+		}
+
+		if count != nil {
+			if err := p.countStock(tx, count); err != nil {
+				return err
+			}
+		}
+
+		if priceTiers == nil {
+			return nil
+		}
+		if err := tx.Where("product_id = ?", productID).Delete(&models.PriceTier{}).Error; err != nil {
+			return err
+		}
+		if len(priceTiers) == 0 {
+			return nil
+		}
+		return tx.Create(&priceTiers).Error
+	})
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,80 @@
+package postgresrepository
+
+import (
+	"bambamload/constant"
+	"bambamload/logger"
+	"bambamload/models"
+
+	"gorm.io/gorm"
+)
+
+// AddProductVariants adds variants to a product, bringing their opening stock in through the stock ledger
+func (p *PostgresRepository) AddProductVariants(productID string, variants []models.ProductVariant, createdBy string) error {
+	err := p.db.Transaction(func(tx *gorm.DB) error {
+		stock := make([]int64, len(variants))
+		for i := range variants {
+			stock[i] = variants[i].CurrentStockQuantity
+			variants[i].CurrentStockQuantity = 0
+		}
+
+		if err := tx.Create(&variants).Error; err != nil {
+			return err
+		}
+
+		for i := range variants {
+			if err := p.receiveInitialStock(tx, productID, variants[i].ID, stock[i], createdBy); err != nil {
+				return err
+			}
+			variants[i].CurrentStockQuantity = stock[i]
+		}
+		return syncVariantStock(tx, productID)
+	})
+	if err != nil {
+		logger.Logger.Errorf("[AddProductVariants]error adding variants to product %s: %s", productID, err)
+		return err
+	}
+	return nil
+}
+
+// UpdateProductVariant updates a variant. A stock count, when given, goes through the stock ledger as an adjustment.
+func (p *PostgresRepository) UpdateProductVariant(productID, variantID string, updates map[string]interface{}, count *models.StockCount) error {
+	err := p.db.Transaction(func(tx *gorm.DB) error {
+		if len(updates) > 0 {
+			err := tx.Model(&models.ProductVariant{}).Where("id = ? AND product_id = ?", variantID, productID).Updates(updates).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if count != nil {
+			if err := p.countStock(tx, count); err != nil {
+				return err
+			}
+		}
+		return syncVariantStock(tx, productID)
+	})
+	if err != nil {
+		logger.Logger.Errorf("[UpdateProductVariant]error updating variant %s: %s", variantID, err)
+		return err
+	}
+	return nil
+}
+
+// SKUExists reports whether any of the skus is already used by a variant
+func (p *PostgresRepository) SKUExists(skus []string) (bool, error) {
+	var count int64
+
+	err := p.db.Model(&models.ProductVariant{}).Where("sku IN ?", skus).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// syncVariantStock sets a product's stock to the total stock of its active variants
+func syncVariantStock(tx *gorm.DB, productID string) error {
+	total := tx.Model(&models.ProductVariant{}).Select("COALESCE(SUM(current_stock_quantity), 0)").
+		Where("product_id = ? AND status = ?", productID, constant.Active)
+
+	return tx.Model(&models.Product{}).Where("id = ?", productID).Update("current_stock_quantity", total).Error
+}
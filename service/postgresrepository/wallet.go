@@ -0,0 +1,155 @@
+package postgresrepository
+
+import (
+	"bambamload/constant"
+	"bambamload/logger"
+	"bambamload/models"
+	"errors"
+	"sort"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInsufficientWalletBalance = errors.New("insufficient wallet balance")
+
+// GetOrCreateWallet fetches the wallet belonging to an owner, creating it if it does not exist
+func (p *PostgresRepository) GetOrCreateWallet(ownerID, walletType string) (*models.Wallet, error) {
+	return p.getOrCreateWallet(p.db, ownerID, walletType)
+}
+
+func (p *PostgresRepository) getOrCreateWallet(tx *gorm.DB, ownerID, walletType string) (*models.Wallet, error) {
+	wallet := &models.Wallet{
+		OwnerID:       ownerID,
+		Type:          walletType,
+		Currency:      constant.KOBO,
+		AllowNegative: walletType == constant.System,
+	}
+
+	err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(wallet).Error
+	if err != nil {
+		return nil, err
+	}
+
+	//the insert is skipped when the wallet already exists, so read back the stored row
+	var existing *models.Wallet
+	err = tx.Where("owner_id = ?", ownerID).First(&existing).Error
+	if err != nil {
+		logger.Logger.Errorf("[GetOrCreateWallet]error getting wallet for %s: %s", ownerID, err)
+		return nil, err
+	}
+	return existing, nil
+}
+
+// GetWalletBalance derives a wallet's balance from its ledger entries
+func (p *PostgresRepository) GetWalletBalance(walletID string) (int64, error) {
+	return p.walletBalance(p.db, walletID)
+}
+
+func (p *PostgresRepository) walletBalance(tx *gorm.DB, walletID string) (int64, error) {
+	var balance int64
+
+	err := tx.Model(&models.LedgerEntry{}).
+		Select("COALESCE(SUM(CASE WHEN entry_type = ? THEN amount ELSE -amount END), 0)", constant.Credit).
+		Where("wallet_id = ?", walletID).
+		Scan(&balance).Error
+	if err != nil {
+		return 0, err
+	}
+	return balance, nil
+}
+
+// PostWalletTransfer records a balanced debit and credit between two wallets.
+// Both wallets are locked for the duration of the transaction so concurrent debits cannot overdraw a wallet.
+// When tx is nil the transfer runs in its own transaction.
+func (p *PostgresRepository) PostWalletTransfer(tx *gorm.DB, transfer models.WalletTransfer) error {
+	if tx == nil {
+		return p.db.Transaction(func(tx *gorm.DB) error {
+			return p.PostWalletTransfer(tx, transfer)
+		})
+	}
+
+	if transfer.Amount <= 0 {
+		return errors.New("transfer amount must be greater than zero")
+	}
+
+	debitWallet, err := p.getOrCreateWallet(tx, transfer.DebitOwnerID, walletTypeFor(transfer.DebitOwnerID))
+	if err != nil {
+		return err
+	}
+	creditWallet, err := p.getOrCreateWallet(tx, transfer.CreditOwnerID, walletTypeFor(transfer.CreditOwnerID))
+	if err != nil {
+		return err
+	}
+
+	//lock in a stable order to avoid deadlocks between opposite transfers
+	ids := []string{debitWallet.ID, creditWallet.ID}
+	sort.Strings(ids)
+	var locked []models.Wallet
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id IN ?", ids).Order("id").Find(&locked).Error
+	if err != nil {
+		return err
+	}
+
+	debitBalance, err := p.walletBalance(tx, debitWallet.ID)
+	if err != nil {
+		return err
+	}
+	if !debitWallet.AllowNegative && debitBalance < transfer.Amount {
+		return ErrInsufficientWalletBalance
+	}
+
+	creditBalance, err := p.walletBalance(tx, creditWallet.ID)
+	if err != nil {
+		return err
+	}
+
+	transaction := &models.WalletTransaction{
+		Reference:   transfer.Reference,
+		Description: transfer.Description,
+		Amount:      transfer.Amount,
+		LedgerEntries: []models.LedgerEntry{
+			{
+				WalletID:     debitWallet.ID,
+				EntryType:    constant.Debit,
+				Amount:       transfer.Amount,
+				BalanceAfter: debitBalance - transfer.Amount,
+				Reference:    transfer.Reference,
+				Description:  transfer.Description,
+			},
+			{
+				WalletID:     creditWallet.ID,
+				EntryType:    constant.Credit,
+				Amount:       transfer.Amount,
+				BalanceAfter: creditBalance + transfer.Amount,
+				Reference:    transfer.Reference,
+				Description:  transfer.Description,
+			},
+		},
+	}
+
+	return tx.Create(transaction).Error
+}
+
+func (p *PostgresRepository) GetWalletStatement(pm *models.PaginationMetadata, walletID string) ([]models.LedgerEntry, *models.PaginationMetadata, error) {
+	var entries []models.LedgerEntry
+
+	query := p.db.Model(&models.LedgerEntry{}).Where("wallet_id = ?", walletID).Order("created_at desc")
+
+	err := query.Scopes(Paginator(pm, &models.LedgerEntry{}, query)).Find(&entries).Error
+	if err != nil {
+		return nil, pm, err
+	}
+
+	return entries, pm, nil
+}
+
+// walletTypeFor tells system wallets apart from user wallets by their owner id
+func walletTypeFor(ownerID string) string {
+	switch ownerID {
+	case constant.PlatformWallet, constant.ClearingWallet:
+		return constant.System
+	default:
+		return constant.User
+	}
+}
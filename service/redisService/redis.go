@@ -33,6 +33,9 @@ type RedisService interface {
 	SetValue(key string, value interface{}, expiration int) error
 	GetValue(key string, target interface{}) error
 	PushToQueue(queue string, msg any) error
+	ReserveStock(item, owner string, quantity, available int64, ttl time.Duration) (bool, error)
+	ReservedStock(item, excludeOwner string) (int64, error)
+	ReleaseStockReservations(owner string) error
 }
 
 func NewRedisService() Redis {
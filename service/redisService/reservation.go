@@ -0,0 +1,103 @@
+package redisService
+
+import (
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// A stock item's holds live in a hash of owner to quantity, with a sorted set of owner to expiry beside it so
+// expired holds can be dropped before holds are counted. Each owner also has a set of the items it holds.
+const (
+	stockReservationPrefix       = "stock_reservation:"
+	stockReservationExpiryPrefix = "stock_reservation_expiry:"
+	stockReservationOwnerPrefix  = "stock_reservation_owner:"
+)
+
+// pruneExpiredHolds is shared by the scripts below. KEYS[1] is the holds hash, KEYS[2] the expiry set and
+// ARGV[1] the owner whose hold is left out of the count.
+const pruneExpiredHolds = `
+local now = redis.call('TIME')
+local nowMs = tonumber(now[1]) * 1000 + math.floor(tonumber(now[2]) / 1000)
+local expired = redis.call('ZRANGEBYSCORE', KEYS[2], '-inf', nowMs)
+for _, owner in ipairs(expired) do
+	redis.call('HDEL', KEYS[1], owner)
+end
+redis.call('ZREMRANGEBYSCORE', KEYS[2], '-inf', nowMs)
+
+local held = 0
+local holds = redis.call('HGETALL', KEYS[1])
+for i = 1, #holds, 2 do
+	if holds[i] ~= ARGV[1] then
+		held = held + tonumber(holds[i + 1])
+	end
+end
+`
+
+// ARGV[2] is the quantity, ARGV[3] the expiry in unix milliseconds and ARGV[4] the available stock.
+// KEYS[3] is the owner's set of items and ARGV[5] the item.
+var reserveStockScript = redis.NewScript(pruneExpiredHolds + `
+if held + tonumber(ARGV[2]) > tonumber(ARGV[4]) then
+	return 0
+end
+
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+redis.call('ZADD', KEYS[2], ARGV[3], ARGV[1])
+redis.call('SADD', KEYS[3], ARGV[5])
+
+local last = redis.call('ZRANGE', KEYS[2], -1, -1, 'WITHSCORES')
+redis.call('PEXPIREAT', KEYS[1], last[2])
+redis.call('PEXPIREAT', KEYS[2], last[2])
+redis.call('PEXPIREAT', KEYS[3], ARGV[3])
+return 1
+`)
+
+var reservedStockScript = redis.NewScript(pruneExpiredHolds + `
+return held
+`)
+
+// StockItem names what a reservation holds: a product variant when one is given, the product otherwise
+func StockItem(productID, variantID string) string {
+	if variantID == "" {
+		return productID
+	}
+	return productID + ":" + variantID
+}
+
+// ReserveStock holds quantity of a stock item for owner until ttl passes, provided the holds of every other
+// owner leave that much of the available stock. Reserving again replaces the owner's earlier hold.
+func (r Redis) ReserveStock(item, owner string, quantity, available int64, ttl time.Duration) (bool, error) {
+	expiresAt := time.Now().Add(ttl).UnixMilli()
+	keys := []string{stockReservationPrefix + item, stockReservationExpiryPrefix + item, stockReservationOwnerPrefix + owner}
+
+	reserved, err := reserveStockScript.Run(ctx, r.Client, keys, owner, quantity, expiresAt, available, item).Int()
+	if err != nil {
+		return false, err
+	}
+	return reserved == 1, nil
+}
+
+// ReservedStock returns how much of a stock item is held by owners other than excludeOwner
+func (r Redis) ReservedStock(item, excludeOwner string) (int64, error) {
+	keys := []string{stockReservationPrefix + item, stockReservationExpiryPrefix + item}
+	return reservedStockScript.Run(ctx, r.Client, keys, excludeOwner).Int64()
+}
+
+// ReleaseStockReservations drops every hold the owner has, such as when a checkout is abandoned or completed
+func (r Redis) ReleaseStockReservations(owner string) error {
+	ownerKey := stockReservationOwnerPrefix + owner
+
+	items, err := r.Client.SMembers(ctx, ownerKey).Result()
+	if err != nil {
+		return err
+	}
+
+	pipe := r.Client.TxPipeline()
+	for _, item := range items {
+		pipe.HDel(ctx, stockReservationPrefix+item, owner)
+		pipe.ZRem(ctx, stockReservationExpiryPrefix+item, owner)
+	}
+	pipe.Del(ctx, ownerKey)
+	_, err = pipe.Exec(ctx)
+	return err
+}
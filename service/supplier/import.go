@@ -0,0 +1,337 @@
+package supplier
+
+import (
+	"bambamload/constant"
+	"bambamload/logger"
+	"bambamload/models"
+	"bambamload/utils"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	maxProductImportRows = 5000
+	//productImportLockTTL bounds how long an import holds its lock, well past the time a full file takes
+	productImportLockTTL = time.Hour
+)
+
+// ImportProducts checks an uploaded CSV or XLSX file of products and processes its rows in the background.
+// Rows with an id update that product; rows without one create a new product.
+func (ss *ServiceSupplier) ImportProducts(data []byte, fileName string, user *models.User) (*models.ProductImport, error) {
+	name, ext := utils.SplitFileName(fileName)
+	ext = strings.ToLower(ext)
+	if ext != constant.CSV && ext != constant.XLSX {
+		return nil, errors.New("only csv and xlsx files are supported")
+	}
+	//uploads look their content type up by the lower case extension
+	fileName = name + ext
+
+	rows, err := utils.ReadSpreadsheet(data, ext)
+	if err != nil {
+		logger.Logger.Errorf("[ImportProducts]Failed to read %s: %v", fileName, err)
+		return nil, errors.New("unable to read file, please check it is a valid csv or xlsx file")
+	}
+	if len(rows) < 2 {
+		return nil, errors.New("file has no product rows")
+	}
+	if len(rows)-1 > maxProductImportRows {
+		return nil, fmt.Errorf("a file can have at most %d product rows", maxProductImportRows)
+	}
+
+	columns := utils.SpreadsheetColumns(rows[0])
+	if _, ok := columns["name"]; !ok {
+		if _, ok = columns["id"]; !ok {
+			return nil, errors.New("file must have a header row with a name or id column")
+		}
+	}
+
+	url, err := ss.UploadService.UploadBytes(data, fileName)
+	if err != nil {
+		logger.Logger.Errorf("[ImportProducts]Failed to upload %s: %v", fileName, err)
+		return nil, errors.New("unable to upload file, please try again later")
+	}
+
+	productImport := &models.ProductImport{
+		SupplierID: user.ID,
+		FileName:   fileName,
+		FileURL:    url,
+		Status:     constant.Pending,
+		TotalRows:  int64(len(rows) - 1),
+	}
+	if err = ss.PostgresRepository.CreateProductImport(productImport); err != nil {
+		return nil, errors.New("unable to start import, please try again later")
+	}
+
+	go ss.runProductImport(productImport, data)
+	return productImport, nil
+}
+
+// ResumeProductImports picks up imports left behind by a restart. Pending imports are started from their uploaded
+// file; imports cut off part way are failed instead, since running them again would create their new products twice.
+func (ss *ServiceSupplier) ResumeProductImports() {
+	imports, err := ss.PostgresRepository.GetStaleProductImports(time.Now().UTC().Add(-time.Minute))
+	if err != nil {
+		return
+	}
+
+	for i := range imports {
+		productImport := &imports[i]
+
+		if productImport.Status == constant.Processing {
+			//an import still being processed holds its lock, so only abandoned ones are failed
+			ss.RedisService.RunWithLock(productImportLock(productImport.ID), productImportLockTTL, func() {
+				_, _ = ss.PostgresRepository.FailProductImport(productImport.ID,
+					"import was interrupted, please check your products before uploading the remaining rows again")
+			})
+			continue
+		}
+
+		data, err := ss.UploadService.Download(productImport.FileURL)
+		if err != nil {
+			logger.Logger.Errorf("[ResumeProductImports]Failed to download file of import %s: %v", productImport.ID, err)
+			_ = ss.PostgresRepository.UpdateProductImport(productImport.ID, map[string]interface{}{
+				"status":         constant.Failed,
+				"failure_reason": "uploaded file could not be read, please upload it again",
+			})
+			continue
+		}
+		ss.runProductImport(productImport, data)
+	}
+}
+
+func (ss *ServiceSupplier) GetProductImports(pm *models.PaginationMetadata, user *models.User) ([]models.ProductImport, *models.PaginationMetadata, error) {
+	imports, paginationMeta, err := ss.PostgresRepository.GetProductImports(pm, user.ID)
+	if err != nil {
+		return nil, pm, errors.New("unable to get imports, please try again later")
+	}
+	return imports, paginationMeta, nil
+}
+
+func (ss *ServiceSupplier) GetProductImport(id string, user *models.User) (*models.ProductImport, error) {
+	productImport, err := ss.PostgresRepository.GetProductImport(id, user.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("import does not exist")
+		}
+		return nil, errors.New("unable to get import, please try again later")
+	}
+	return productImport, nil
+}
+
+// runProductImport processes an import under its lock, so only one instance ever works on it
+func (ss *ServiceSupplier) runProductImport(productImport *models.ProductImport, data []byte) {
+	ss.RedisService.RunWithLock(productImportLock(productImport.ID), productImportLockTTL, func() {
+		ss.processProductImport(productImport, data)
+	})
+}
+
+func productImportLock(id string) string {
+	return fmt.Sprintf("%s:%s", constant.ProductImportLock, id)
+}
+
+// processProductImport saves each row on its own so one bad row does not hold back the rest, then uploads
+// a report of how every row went
+func (ss *ServiceSupplier) processProductImport(productImport *models.ProductImport, data []byte) {
+	claimed, err := ss.PostgresRepository.ClaimProductImport(productImport.ID)
+	if err != nil || !claimed {
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Logger.Errorf("[processProductImport]import %s stopped: %v", productImport.ID, r)
+			_ = ss.PostgresRepository.UpdateProductImport(productImport.ID, map[string]interface{}{
+				"status":         constant.Failed,
+				"failure_reason": "import stopped unexpectedly, please try again",
+			})
+		}
+	}()
+
+	user, err := ss.PostgresRepository.GetUser(productImport.SupplierID, constant.ID)
+	if err != nil {
+		_ = ss.PostgresRepository.UpdateProductImport(productImport.ID, map[string]interface{}{
+			"status":         constant.Failed,
+			"failure_reason": "unable to process import, please try again",
+		})
+		return
+	}
+
+	//the file was checked when it was uploaded
+	_, ext := utils.SplitFileName(productImport.FileName)
+	rows, err := utils.ReadSpreadsheet(data, strings.ToLower(ext))
+	if err != nil || len(rows) < 2 {
+		logger.Logger.Errorf("[processProductImport]Failed to read file of import %s: %v", productImport.ID, err)
+		_ = ss.PostgresRepository.UpdateProductImport(productImport.ID, map[string]interface{}{
+			"status":         constant.Failed,
+			"failure_reason": "unable to read file, please upload it again",
+		})
+		return
+	}
+	columns := utils.SpreadsheetColumns(rows[0])
+
+	var created, updated, failed int64
+	report := [][]string{{"row", "status", "product_id", "name", "error"}}
+
+	for i, record := range rows[1:] {
+		//row 1 is the header
+		result := ss.importProductRow(columns, record, user)
+		result.Row = i + 2
+
+		switch result.Status {
+		case constant.Created:
+			created++
+		case constant.Updated:
+			updated++
+		default:
+			failed++
+		}
+		report = append(report, []string{strconv.Itoa(result.Row), result.Status, result.ProductID, result.Name, result.Error})
+	}
+
+	updates := map[string]interface{}{
+		"status":        constant.Completed,
+		"created_count": created,
+		"updated_count": updated,
+		"failed_count":  failed,
+	}
+
+	reportData, err := utils.BuildCSV(report)
+	if err == nil {
+		var url string
+		url, err = ss.UploadService.UploadBytes(reportData, fmt.Sprintf("product_import_%s_report.csv", productImport.ID))
+		updates["report_url"] = url
+	}
+	if err != nil {
+		logger.Logger.Errorf("[processProductImport]Failed to save report for import %s: %v", productImport.ID, err)
+		updates["failure_reason"] = "products were imported but the report could not be saved"
+	}
+
+	_ = ss.PostgresRepository.UpdateProductImport(productImport.ID, updates)
+}
+
+// importProductRow creates or updates the product on one row, returning why the row was skipped when it fails
+func (ss *ServiceSupplier) importProductRow(columns map[string]int, record []string, user *models.User) models.ProductImportRow {
+	cell := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	result := models.ProductImportRow{ProductID: cell("id"), Name: cell("name"), Status: constant.Failed}
+
+	product, stock, err := parseProductImportRow(cell)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	if result.ProductID == "" {
+		if err = validateNewProduct(product); err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		if stock != nil {
+			product.CurrentStockQuantity = *stock
+		}
+
+		if err = ss.CreateProduct(product, user); err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		result.ProductID, result.Status = product.ID, constant.Created
+		return result
+	}
+
+	existing, err := ss.getOwnProduct(result.ProductID, user)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if stock != nil && existing.HasVariants() {
+		result.Error = "stock of a product sold by variant is set on its variants"
+		return result
+	}
+
+	if err = ss.EditProduct(existing.ID, &models.EditProductRequest{Product: *product, CurrentStockQuantity: stock}, user); err != nil {
+		logger.Logger.Errorf("[importProductRow]Failed to update product %s: %v", existing.ID, err)
+		result.Error = "unable to update product"
+		return result
+	}
+	result.Status = constant.Updated
+	if result.Name == "" {
+		result.Name = existing.Name
+	}
+	return result
+}
+
+// parseProductImportRow reads a row into a product, with the stock level apart so a blank cell can be told from zero
+func parseProductImportRow(cell func(string) string) (*models.Product, *int64, error) {
+	product := &models.Product{
+		Name:                  cell("name"),
+		CategoryID:            cell("category_id"),
+		Category:              cell("category"),
+		Type:                  cell("type"),
+		Description:           cell("description"),
+		Unit:                  cell("unit"),
+		PaymentTerms:          strings.ToLower(cell("payment_terms")),
+		PaymentMethods:        cell("payment_methods"),
+		FulfilmentType:        strings.ToLower(cell("fulfilment_type")),
+		EstimatedDeliveryTime: cell("estimated_delivery_time"),
+	}
+
+	if product.PaymentTerms != "" && product.PaymentTerms != constant.Prepayment && product.PaymentTerms != constant.PayOnDelivery {
+		return nil, nil, errors.New("payment_terms can only be prepayment/pay_on_delivery")
+	}
+	if product.FulfilmentType != "" && product.FulfilmentType != constant.Delivery &&
+		product.FulfilmentType != constant.CustomerPickUp && product.FulfilmentType != constant.Both {
+		return nil, nil, errors.New("fulfilment_type can only be delivery/customer_pick_up/both")
+	}
+
+	var stock *int64
+	for _, field := range []struct {
+		column string
+		value  *int64
+	}{
+		{"base_unit_price", &product.BaseUnitPrice},
+		{"minimum_order_quantity", &product.MinimumOrderQuantity},
+		{"low_stock_alert_level", &product.LowStockAlertLevel},
+		{"current_stock_quantity", nil},
+	} {
+		raw := cell(field.column)
+		if raw == "" {
+			continue
+		}
+
+		value, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || value < 0 {
+			return nil, nil, fmt.Errorf("%s must be a whole number that is not negative", field.column)
+		}
+		if field.value == nil {
+			stock = &value
+			continue
+		}
+		*field.value = value
+	}
+	return product, stock, nil
+}
+
+// validateNewProduct checks the fields the create product endpoint requires
+func validateNewProduct(product *models.Product) error {
+	if product.Name == "" {
+		return errors.New("name cannot be empty")
+	}
+	if product.CategoryID == "" && product.Category == "" {
+		return errors.New("category_id or category cannot be empty")
+	}
+	if product.BaseUnitPrice <= 0 {
+		return errors.New("base_unit_price must be greater than zero")
+	}
+	return nil
+}
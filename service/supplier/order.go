@@ -0,0 +1,387 @@
+package supplier
+
+import (
+	"bambamload/constant"
+	"bambamload/logger"
+	"bambamload/models"
+	"bambamload/service/postgresrepository"
+	"bambamload/utils"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+func (ss *ServiceSupplier) GetOrders(pm *models.PaginationMetadata, status string, user *models.User) ([]models.Order, *models.PaginationMetadata, error) {
+	orders, paginationMetaData, err := ss.PostgresRepository.GetOrders(pm, models.OrderFilter{
+		SupplierID: user.ID,
+		Status:     status,
+	})
+	if err != nil {
+		logger.Logger.Errorf("[GetOrders]Failed to get supplier orders: %v", err)
+		return nil, pm, errors.New("unable to get orders")
+	}
+	return orders, paginationMetaData, nil
+}
+
+func (ss *ServiceSupplier) GetOrder(id string, user *models.User) (*models.Order, error) {
+	order, err := ss.PostgresRepository.GetOrder(id, constant.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("order does not exist")
+		}
+		return nil, errors.New("unable to get order")
+	}
+	if order.SupplierID != user.ID {
+		return nil, errors.New("order does not exist")
+	}
+	return order, nil
+}
+
+func (ss *ServiceSupplier) AcceptOrder(id, note string, user *models.User) error {
+	order, err := ss.GetOrder(id, user)
+	if err != nil {
+		return err
+	}
+
+	if err = utils.ValidateOrderTransition(order.Status, constant.Confirmed); err != nil {
+		return err
+	}
+	if order.PaymentTerms == constant.Prepayment && order.PaymentStatus != constant.Paid {
+		return errors.New("order has not been paid for yet")
+	}
+
+	err = ss.PostgresRepository.ChangeOrderStatus(models.OrderStatusChange{
+		Order:     order,
+		Status:    constant.Confirmed,
+		ChangedBy: user,
+		Note:      note,
+	}, nil)
+	if err != nil {
+		if errors.Is(err, postgresrepository.ErrOrderStatusChanged) {
+			return err
+		}
+		return errors.New("unable to accept order, please try again later")
+	}
+
+	ss.notifyBuyer(order, "Your order has been accepted",
+		fmt.Sprintf("%s has accepted your order and is preparing it.", order.Supplier.BusinessName))
+	return nil
+}
+
+// RejectOrder turns down a pending order. Stock is restored and an order that was paid for up front is
+// refunded back through the payment provider; the refund is returned for processing.
+func (ss *ServiceSupplier) RejectOrder(id, reason string, user *models.User) (*models.Refund, error) {
+	order, err := ss.GetOrder(id, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = utils.ValidateOrderTransition(order.Status, constant.Rejected); err != nil {
+		return nil, err
+	}
+
+	refund, err := ss.PostgresRepository.CancelOrder(models.OrderStatusChange{
+		Order:     order,
+		Status:    constant.Rejected,
+		ChangedBy: user,
+		Note:      reason,
+		Updates:   map[string]interface{}{"reject_reason": reason},
+	}, constant.Provider)
+	if err != nil {
+		if errors.Is(err, postgresrepository.ErrOrderStatusChanged) {
+			return nil, err
+		}
+		return nil, errors.New("unable to reject order, please try again later")
+	}
+
+	ss.notifyBuyer(order, "Your order has been rejected",
+		fmt.Sprintf("%s could not fulfil your order.\n\nReason: %s", order.Supplier.BusinessName, reason))
+	return refund, nil
+}
+
+// CancelOrder cancels an accepted order the supplier can no longer fulfil. Stock is restored and a paid order
+// is refunded back through the payment provider; the refund is returned for processing.
+func (ss *ServiceSupplier) CancelOrder(id, reason string, user *models.User) (*models.Refund, error) {
+	order, err := ss.GetOrder(id, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.Status != constant.Pending && order.Status != constant.Confirmed {
+		return nil, errors.New("only orders that have not been dispatched can be cancelled")
+	}
+
+	refund, err := ss.PostgresRepository.CancelOrder(models.OrderStatusChange{
+		Order:     order,
+		ChangedBy: user,
+		Note:      reason,
+	}, constant.Provider)
+	if err != nil {
+		if errors.Is(err, postgresrepository.ErrOrderStatusChanged) {
+			return nil, err
+		}
+		return nil, errors.New("unable to cancel order, please try again later")
+	}
+
+	message := fmt.Sprintf("%s has cancelled your order.\n\nReason: %s", order.Supplier.BusinessName, reason)
+	if refund != nil {
+		message += fmt.Sprintf("\n\nYour payment of %s %d will be refunded to you.", constant.NGN, order.TotalAmount)
+	}
+	ss.notifyBuyer(order, "Your order has been cancelled", message)
+	return refund, nil
+}
+
+// DispatchOrder ships some or all of the outstanding quantity on an order. The first shipment moves the order
+// in transit; further shipments carry the rest of the order or fill backorders.
+func (ss *ServiceSupplier) DispatchOrder(id string, req models.DispatchOrderRequest, user *models.User) (*models.Order, error) {
+	order, err := ss.GetOrder(id, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.Status != constant.InTransit {
+		if err = utils.ValidateOrderTransition(order.Status, constant.InTransit); err != nil {
+			return nil, err
+		}
+	}
+
+	shipmentItems, err := buildShipmentItems(order, req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	shipment := &models.Shipment{
+		OrderID:          order.ID,
+		Reference:        utils.GenerateReference("SHP"),
+		WaybillReference: req.WaybillReference,
+		Note:             req.Note,
+		ShipmentItems:    shipmentItems,
+	}
+
+	if order.Status == constant.InTransit {
+		err = ss.PostgresRepository.SaveShipment(nil, shipment)
+	} else {
+		err = ss.PostgresRepository.ChangeOrderStatus(models.OrderStatusChange{
+			Order:     order,
+			Status:    constant.InTransit,
+			ChangedBy: user,
+			Note:      req.Note,
+			Updates:   map[string]interface{}{"waybill_reference": req.WaybillReference},
+		}, func(tx *gorm.DB) error {
+			return ss.PostgresRepository.SaveShipment(tx, shipment)
+		})
+	}
+	if err != nil {
+		if errors.Is(err, postgresrepository.ErrOrderStatusChanged) || errors.Is(err, postgresrepository.ErrInsufficientStock) ||
+			errors.Is(err, postgresrepository.ErrQuantityExceeded) {
+			return nil, err
+		}
+		return nil, errors.New("unable to dispatch order, please try again later")
+	}
+
+	var shipped, outstanding int64
+	for _, item := range shipmentItems {
+		shipped += item.Quantity
+	}
+	for _, item := range order.OrderItems {
+		outstanding += item.OutstandingQuantity()
+	}
+
+	if shipped < outstanding {
+		ss.notifyBuyer(order, "Part of your order is on its way",
+			fmt.Sprintf("%s has dispatched %d of the %d outstanding units on your order.\n\nWaybill reference: %s",
+				order.Supplier.BusinessName, shipped, outstanding, req.WaybillReference))
+	} else {
+		ss.notifyBuyer(order, "Your order is on its way",
+			fmt.Sprintf("%s has dispatched your order.\n\nWaybill reference: %s", order.Supplier.BusinessName, req.WaybillReference))
+	}
+	return order, nil
+}
+
+// buildShipmentItems turns the requested quantities into shipment items. No items means ship everything outstanding.
+func buildShipmentItems(order *models.Order, items []models.ShipmentItemRequest) ([]models.ShipmentItem, error) {
+	orderItems := make(map[string]models.OrderItem, len(order.OrderItems))
+	for _, item := range order.OrderItems {
+		orderItems[item.ID] = item
+	}
+
+	quantities := make(map[string]int64)
+	if len(items) == 0 {
+		for _, item := range order.OrderItems {
+			if item.OutstandingQuantity() > 0 {
+				quantities[item.ID] = item.OutstandingQuantity()
+			}
+		}
+	}
+	for _, item := range items {
+		if _, ok := orderItems[item.OrderItemID]; !ok {
+			return nil, errors.New("order item does not exist")
+		}
+		if item.Quantity <= 0 {
+			return nil, errors.New("quantity must be greater than zero")
+		}
+		quantities[item.OrderItemID] += item.Quantity
+	}
+
+	if len(quantities) == 0 {
+		return nil, errors.New("there is nothing left to ship on this order")
+	}
+
+	shipmentItems := make([]models.ShipmentItem, 0, len(quantities))
+	for _, item := range order.OrderItems {
+		quantity, ok := quantities[item.ID]
+		if !ok {
+			continue
+		}
+		if quantity > item.OutstandingQuantity() {
+			return nil, fmt.Errorf("only %d units of %s are left to ship", item.OutstandingQuantity(), item.ProductName)
+		}
+		shipmentItems = append(shipmentItems, models.ShipmentItem{
+			OrderItemID: item.ID,
+			ProductID:   item.ProductID,
+			Quantity:    quantity,
+		})
+	}
+	return shipmentItems, nil
+}
+
+// ResolveUnfilledItems backorders or cancels the quantity on order lines that could not be shipped.
+// Cancelled units are taken off the order and refunded to the buyer when the order has been paid for.
+func (ss *ServiceSupplier) ResolveUnfilledItems(id string, req models.ResolveUnfilledRequest, user *models.User) error {
+	order, err := ss.GetOrder(id, user)
+	if err != nil {
+		return err
+	}
+
+	if order.Status != constant.Confirmed && order.Status != constant.InTransit {
+		return fmt.Errorf("unfilled items cannot be resolved on a %s order", order.Status)
+	}
+
+	orderItems := make(map[string]models.OrderItem, len(order.OrderItems))
+	for _, item := range order.OrderItems {
+		orderItems[item.ID] = item
+	}
+
+	actions := make(map[string]string, len(req.Items))
+	for _, item := range req.Items {
+		if _, ok := orderItems[item.OrderItemID]; !ok {
+			return errors.New("order item does not exist")
+		}
+		if item.Action != constant.Backorder && item.Action != constant.Cancel {
+			return errors.New("action can only be backorder/cancel")
+		}
+		actions[item.OrderItemID] = item.Action
+	}
+
+	//an order with nothing left to deliver should be rejected or cancelled as a whole
+	var remaining int64
+	for _, item := range order.OrderItems {
+		if actions[item.ID] != constant.Cancel {
+			remaining += item.Quantity - item.CancelledQuantity
+		} else {
+			remaining += item.ShippedQuantity
+		}
+	}
+	if remaining == 0 {
+		return errors.New("this would cancel the whole order, reject or cancel the order instead")
+	}
+
+	cancelled, err := ss.PostgresRepository.ResolveUnfilledItems(order, actions, req.Note)
+	if err != nil {
+		if errors.Is(err, postgresrepository.ErrOrderStatusChanged) || errors.Is(err, postgresrepository.ErrNothingOutstanding) {
+			return err
+		}
+		return errors.New("unable to update order items, please try again later")
+	}
+
+	var lines []string
+	for _, item := range req.Items {
+		orderItem := orderItems[item.OrderItemID]
+		if item.Action == constant.Backorder {
+			lines = append(lines, fmt.Sprintf("%s: backordered, it will ship once back in stock", orderItem.ProductName))
+		} else {
+			lines = append(lines, fmt.Sprintf("%s: %d units cancelled", orderItem.ProductName, orderItem.OutstandingQuantity()))
+		}
+	}
+	message := fmt.Sprintf("%s could not ship part of your order.\n\n%s", order.Supplier.BusinessName, strings.Join(lines, "\n"))
+	if cancelled > 0 && order.PaymentStatus == constant.Paid {
+		message += fmt.Sprintf("\n\n%s %d has been refunded to your wallet.", constant.NGN, cancelled)
+	}
+	ss.notifyBuyer(order, "Update on your order", message)
+	return nil
+}
+
+// ConfirmDelivery records the handover of an order. Pay on delivery orders are completed straight away
+// because the cash has been collected, which also records their settlement. Both changes happen in one
+// transaction; the delivery code is cleared once it has committed.
+func (ss *ServiceSupplier) ConfirmDelivery(order *models.Order, note string, user *models.User) error {
+	if err := utils.ValidateOrderTransition(order.Status, constant.Delivered); err != nil {
+		return err
+	}
+	for _, item := range order.OrderItems {
+		if item.OutstandingQuantity() > 0 {
+			return fmt.Errorf("%d units of %s have not been shipped or cancelled yet", item.OutstandingQuantity(), item.ProductName)
+		}
+	}
+
+	changes := []models.OrderStatusChange{{
+		Order:     order,
+		Status:    constant.Delivered,
+		ChangedBy: user,
+		Note:      note,
+	}}
+	if order.PaymentTerms == constant.PayOnDelivery {
+		changes = append(changes, models.OrderStatusChange{
+			Order:     order,
+			Status:    constant.Completed,
+			ChangedBy: user,
+			Note:      "cash collected on delivery",
+			Updates:   map[string]interface{}{"payment_status": constant.Paid},
+		})
+	}
+
+	err := ss.PostgresRepository.ChangeOrderStatuses(changes, nil)
+	if err != nil {
+		if errors.Is(err, postgresrepository.ErrOrderStatusChanged) {
+			return err
+		}
+		logger.Logger.Errorf("[ConfirmDelivery]Failed to confirm delivery of order %s: %v", order.Reference, err)
+		return errors.New("unable to confirm delivery, please try again later")
+	}
+
+	if order.PaymentTerms != constant.PayOnDelivery {
+		ss.notifyBuyer(order, "Your order has been delivered",
+			fmt.Sprintf("%s has marked your order as delivered. Please confirm receipt once you have checked your goods.", order.Supplier.BusinessName))
+		return nil
+	}
+
+	ss.clearDeliveryOtp(order)
+
+	ss.notifyBuyer(order, "Your order has been delivered",
+		fmt.Sprintf("Your delivery from %s has been confirmed and your payment of %s %d recorded.", order.Supplier.BusinessName, constant.NGN, order.TotalAmount))
+	return nil
+}
+
+// clearDeliveryOtp invalidates an order's delivery code and its failed attempts once the handover is recorded
+func (ss *ServiceSupplier) clearDeliveryOtp(order *models.Order) {
+	err := ss.RedisService.GetRedisClient().Del(context.Background(),
+		fmt.Sprintf("%s:%s", constant.DeliveryOtp, order.Reference),
+		fmt.Sprintf("%s:%s", constant.DeliveryOtpAttempts, order.Reference),
+	).Err()
+	if err != nil {
+		logger.Logger.Errorf("[clearDeliveryOtp]Failed to clear delivery code of order %s: %v", order.Reference, err)
+	}
+}
+
+// notifyBuyer emails the buyer about a change on their order
+func (ss *ServiceSupplier) notifyBuyer(order *models.Order, title, message string) {
+	body := utils.BuildOrderUpdateEmail(order.Buyer.Name, order.Reference, title, message)
+
+	err := ss.EmailService.Send(order.Buyer.Email, fmt.Sprintf("%s - %s", title, order.Reference), body)
+	if err != nil {
+		logger.Logger.Errorf("[notifyBuyer]Failed to send email for order %s: %v", order.Reference, err)
+	}
+}
@@ -4,11 +4,169 @@ import (
 	"bambamload/constant"
 	"bambamload/logger"
 	"bambamload/models"
+	"bambamload/service/postgresrepository"
+	"bambamload/utils"
+	"errors"
+
+	"gorm.io/gorm"
 )
 
 func (ss *ServiceSupplier) CreateProduct(req *models.Product, user *models.User) error {
 	req.SupplierID = user.ID
-	return ss.PostgresRepository.CreateProduct(req)
+	if err := ss.fileUnderCategory(req, req.CategoryID, req.Category, req.Type); err != nil {
+		return err
+	}
+	for i := range req.PriceTiers {
+		req.PriceTiers[i].Model = models.Model{}
+	}
+
+	if req.HasVariants() {
+		if err := ss.prepareVariants(req.Variants); err != nil {
+			return err
+		}
+
+		//a product sold by variant holds the total of its variants' stock
+		req.CurrentStockQuantity = 0
+		for _, variant := range req.Variants {
+			req.CurrentStockQuantity += variant.CurrentStockQuantity
+		}
+	}
+
+	if err := ss.PostgresRepository.CreateProduct(req); err != nil {
+		logger.Logger.Errorf("[CreateProduct]Failed to create product: %v", err)
+		return errors.New("create product failed")
+	}
+	return nil
+}
+
+// AddProductVariants adds variants to one of the supplier's products
+func (ss *ServiceSupplier) AddProductVariants(productID string, variants []models.ProductVariant, user *models.User) error {
+	product, err := ss.getOwnProduct(productID, user)
+	if err != nil {
+		return err
+	}
+
+	if err = ss.prepareVariants(variants); err != nil {
+		return err
+	}
+	for i := range variants {
+		variants[i].ProductID = product.ID
+	}
+
+	if err = ss.PostgresRepository.AddProductVariants(product.ID, variants, user.ID); err != nil {
+		return errors.New("unable to add variants, please try again later")
+	}
+	return nil
+}
+
+func (ss *ServiceSupplier) EditProductVariant(productID, variantID string, req models.EditProductVariantRequest, user *models.User) error {
+	product, err := ss.getOwnProduct(productID, user)
+	if err != nil {
+		return err
+	}
+	if _, ok := product.Variant(variantID); !ok {
+		return errors.New("variant does not exist")
+	}
+
+	updates := make(map[string]interface{})
+	if req.Name != "" {
+		updates["name"] = req.Name
+	}
+	if req.UnitPrice > 0 {
+		updates["unit_price"] = req.UnitPrice
+	}
+	var count *models.StockCount
+	if req.CurrentStockQuantity != nil {
+		if *req.CurrentStockQuantity < 0 {
+			return errors.New("stock quantity cannot be negative")
+		}
+		count = &models.StockCount{
+			ProductID: product.ID,
+			VariantID: variantID,
+			Quantity:  *req.CurrentStockQuantity,
+			Reason:    "stock level edited",
+			CreatedBy: user.ID,
+		}
+	}
+	if req.LowStockAlertLevel != nil {
+		if *req.LowStockAlertLevel < 0 {
+			return errors.New("low stock alert level cannot be negative")
+		}
+		updates["low_stock_alert_level"] = *req.LowStockAlertLevel
+	}
+	if req.Status != "" {
+		if req.Status != constant.Active && req.Status != constant.Deactivated {
+			return errors.New("status can only be active/deactivated")
+		}
+		updates["status"] = req.Status
+	}
+	if len(updates) == 0 && count == nil {
+		return errors.New("nothing to update")
+	}
+
+	if err = ss.PostgresRepository.UpdateProductVariant(product.ID, variantID, updates, count); err != nil {
+		return errors.New("unable to update variant, please try again later")
+	}
+	return nil
+}
+
+// fileUnderCategory finds the category a product belongs in, by id or by its category and type names, and sets
+// the product's category id and names from the category tree
+func (ss *ServiceSupplier) fileUnderCategory(product *models.Product, categoryID, categoryName, typeName string) error {
+	path, err := ss.PostgresRepository.ResolveCategory(categoryID, categoryName, typeName)
+	if err != nil {
+		if errors.Is(err, postgresrepository.ErrCategoryNotFound) || errors.Is(err, postgresrepository.ErrCategoryInactive) ||
+			errors.Is(err, postgresrepository.ErrCategoryNotLeaf) {
+			return err
+		}
+		logger.Logger.Errorf("[fileUnderCategory]Failed to resolve category %s/%s/%s: %v", categoryID, categoryName, typeName, err)
+		return errors.New("unable to check product category, please try again later")
+	}
+
+	category := path[len(path)-1]
+	product.CategoryID = category.ID
+	product.Category = path[0].Name
+	product.Type = category.Name
+	return nil
+}
+
+// getOwnProduct fetches a product that belongs to the supplier
+func (ss *ServiceSupplier) getOwnProduct(id string, user *models.User) (*models.Product, error) {
+	product, err := ss.PostgresRepository.GetProduct(id, constant.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("product does not exist")
+		}
+		return nil, errors.New("unable to get product")
+	}
+	if product.SupplierID != user.ID {
+		return nil, errors.New("product does not exist")
+	}
+	return product, nil
+}
+
+// prepareVariants validates new variants, checks their SKUs are not taken and clears any client supplied ids
+func (ss *ServiceSupplier) prepareVariants(variants []models.ProductVariant) error {
+	if err := utils.ValidateProductVariants(variants); err != nil {
+		return err
+	}
+
+	skus := make([]string, 0, len(variants))
+	for i := range variants {
+		variants[i].Model = models.Model{}
+		variants[i].Status = constant.Active
+		skus = append(skus, variants[i].SKU)
+	}
+
+	exists, err := ss.PostgresRepository.SKUExists(skus)
+	if err != nil {
+		logger.Logger.Errorf("[prepareVariants]Failed to check skus: %v", err)
+		return errors.New("unable to save variants, please try again later")
+	}
+	if exists {
+		return errors.New("one or more skus are already in use")
+	}
+	return nil
 }
 
 func (ss *ServiceSupplier) SupplierGetProduct(id string, user *models.User) (*models.Product, error) {
@@ -20,9 +178,9 @@ func (ss *ServiceSupplier) SupplierGetProduct(id string, user *models.User) (*mo
 	return product, nil
 }
 
-func (ss *ServiceSupplier) GetProducts(pm *models.PaginationMetadata, status, searchText, productType string) ([]models.Product, *models.PaginationMetadata, error) {
+func (ss *ServiceSupplier) GetProducts(pm *models.PaginationMetadata, filter models.ProductFilter) ([]models.Product, *models.PaginationMetadata, error) {
 
-	products, paginationMetaData, err := ss.PostgresRepository.GetProducts(pm, status, searchText, productType)
+	products, paginationMetaData, err := ss.PostgresRepository.GetProducts(pm, filter)
 	if err != nil {
 		logger.Logger.Errorf("Supplier Get Products Error: %s", err)
 		return nil, paginationMetaData, err
@@ -30,7 +188,21 @@ func (ss *ServiceSupplier) GetProducts(pm *models.PaginationMetadata, status, se
 	return products, paginationMetaData, nil
 }
 
-func (ss *ServiceSupplier) EditProduct(id string, product *models.Product, user *models.User) error {
+// GetProductFacets counts the products matching a filter by category, type, fulfilment type and price band
+func (ss *ServiceSupplier) GetProductFacets(filter models.ProductFilter) (*models.ProductFacets, error) {
+	facets, err := ss.PostgresRepository.GetProductFacets(filter)
+	if err != nil {
+		return nil, errors.New("unable to get product facets, please try again later")
+	}
+	return facets, nil
+}
+
+func (ss *ServiceSupplier) EditProduct(id string, req *models.EditProductRequest, user *models.User) error {
+	existing, err := ss.getOwnProduct(id, user)
+	if err != nil {
+		return err
+	}
+	product := &req.Product
 
 	updateMap := make(map[string]interface{})
 
@@ -38,11 +210,18 @@ func (ss *ServiceSupplier) EditProduct(id string, product *models.Product, user
 		updateMap["name"] = product.Name
 	}
 
-	if product.Category != "" {
-		updateMap["category"] = product.Category
-	}
+	//a new category is checked against the category tree, keeping the current top-level category when only the type changes
+	if product.CategoryID != "" || product.Category != "" || product.Type != "" {
+		categoryName := product.Category
+		if product.CategoryID == "" && categoryName == "" {
+			categoryName = existing.Category
+		}
 
-	if product.Type != "" {
+		if err := ss.fileUnderCategory(product, product.CategoryID, categoryName, product.Type); err != nil {
+			return err
+		}
+		updateMap["category_id"] = product.CategoryID
+		updateMap["category"] = product.Category
 		updateMap["type"] = product.Type
 	}
 
@@ -66,8 +245,21 @@ func (ss *ServiceSupplier) EditProduct(id string, product *models.Product, user
 		updateMap["payment_methods"] = product.PaymentMethods
 	}
 
-	if product.CurrentStockQuantity > 0 {
-		updateMap["current_stock_quantity"] = product.CurrentStockQuantity
+	//stock is set through the stock ledger, and for a product sold by variant it is edited on the variants
+	var count *models.StockCount
+	if req.CurrentStockQuantity != nil {
+		if *req.CurrentStockQuantity < 0 {
+			return errors.New("stock quantity cannot be negative")
+		}
+
+		if !existing.HasVariants() {
+			count = &models.StockCount{
+				ProductID: id,
+				Quantity:  *req.CurrentStockQuantity,
+				Reason:    "stock level edited",
+				CreatedBy: user.ID,
+			}
+		}
 	}
 
 	if product.LowStockAlertLevel > 0 {
@@ -81,7 +273,13 @@ func (ss *ServiceSupplier) EditProduct(id string, product *models.Product, user
 		updateMap["estimated_delivery_time"] = product.EstimatedDeliveryTime
 	}
 
-	err := ss.PostgresRepository.SupplierEditProduct(id, updateMap)
+	//tiers are always replaced as a set
+	for i := range product.PriceTiers {
+		product.PriceTiers[i].Model = models.Model{}
+		product.PriceTiers[i].ProductID = id
+	}
+
+	err = ss.PostgresRepository.SupplierEditProduct(id, updateMap, product.PriceTiers, count)
 	if err != nil {
 		logger.Logger.Errorf("SupplierEditProduct Error: %v", err)
 		return err
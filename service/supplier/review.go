@@ -0,0 +1,44 @@
+package supplier
+
+import (
+	"bambamload/logger"
+	"bambamload/models"
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// GetReviews lists the reviews of the supplier's products
+func (ss *ServiceSupplier) GetReviews(pm *models.PaginationMetadata, user *models.User) ([]models.Review, *models.PaginationMetadata, error) {
+	reviews, paginationMetaData, err := ss.PostgresRepository.GetReviews(pm, models.ReviewFilter{SupplierID: user.ID})
+	if err != nil {
+		return nil, pm, errors.New("unable to get reviews, please try again later")
+	}
+	return reviews, paginationMetaData, nil
+}
+
+// ReplyToReview publishes the supplier's one reply to a review of one of their products
+func (ss *ServiceSupplier) ReplyToReview(id string, req models.ReplyToReviewRequest, user *models.User) (*models.Review, error) {
+	review, err := ss.PostgresRepository.GetReview(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("review does not exist")
+		}
+		logger.Logger.Errorf("[ReplyToReview]Failed to get review %s: %v", id, err)
+		return nil, errors.New("unable to reply to review, please try again later")
+	}
+
+	if _, err = ss.getOwnProduct(review.ProductID, user); err != nil {
+		return nil, errors.New("review does not exist")
+	}
+
+	replied, err := ss.PostgresRepository.ReplyToReview(review.ID, strings.TrimSpace(req.Reply))
+	if err != nil {
+		return nil, errors.New("unable to reply to review, please try again later")
+	}
+	if !replied {
+		return nil, errors.New("review has already been replied to")
+	}
+	return ss.PostgresRepository.GetReview(review.ID)
+}
@@ -0,0 +1,270 @@
+package supplier
+
+import (
+	"bambamload/constant"
+	"bambamload/enum"
+	"bambamload/logger"
+	"bambamload/models"
+	"bambamload/service/postgresrepository"
+	"bambamload/utils"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GetRfqs lists the open RFQs the supplier can quote on
+func (ss *ServiceSupplier) GetRfqs(pm *models.PaginationMetadata, user *models.User) ([]models.Rfq, *models.PaginationMetadata, error) {
+	rfqs, paginationMetaData, err := ss.PostgresRepository.GetRfqs(pm, models.RfqFilter{
+		SupplierID: user.ID,
+		Status:     constant.Open,
+	})
+	if err != nil {
+		logger.Logger.Errorf("[GetRfqs]Failed to get supplier rfqs: %v", err)
+		return nil, pm, errors.New("unable to get rfqs")
+	}
+	return rfqs, paginationMetaData, nil
+}
+
+// GetRfq fetches an RFQ the supplier can quote on, with only the supplier's own quotes
+func (ss *ServiceSupplier) GetRfq(id string, user *models.User) (*models.Rfq, error) {
+	rfq, err := ss.PostgresRepository.GetRfq(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("rfq does not exist")
+		}
+		return nil, errors.New("unable to get rfq")
+	}
+
+	quotes := make([]models.Quote, 0)
+	for _, quote := range rfq.Quotes {
+		if quote.SupplierID == user.ID {
+			quotes = append(quotes, quote)
+		}
+	}
+	rfq.Quotes = quotes
+
+	if len(quotes) > 0 {
+		return rfq, nil
+	}
+	if rfq.ProductID != "" {
+		if rfq.Product == nil || rfq.Product.SupplierID != user.ID {
+			return nil, errors.New("rfq does not exist")
+		}
+		return rfq, nil
+	}
+
+	sells, err := ss.PostgresRepository.SupplierSellsInCategory(user.ID, rfq.Category)
+	if err != nil {
+		logger.Logger.Errorf("[GetRfq]Failed to check supplier category: %v", err)
+		return nil, errors.New("unable to get rfq")
+	}
+	if !sells {
+		return nil, errors.New("rfq does not exist")
+	}
+	return rfq, nil
+}
+
+// SubmitQuote answers an RFQ with a priced offer on one of the supplier's products
+func (ss *ServiceSupplier) SubmitQuote(rfqID string, req models.SubmitQuoteRequest, user *models.User) (*models.Quote, error) {
+	rfq, err := ss.GetRfq(rfqID, user)
+	if err != nil {
+		return nil, err
+	}
+	if rfq.Status != constant.Open {
+		return nil, fmt.Errorf("rfq is %s", rfq.Status)
+	}
+	for _, quote := range rfq.Quotes {
+		if quote.Status == constant.Pending {
+			return nil, errors.New("you already have a pending quote on this rfq")
+		}
+	}
+
+	productID := req.ProductID
+	if productID == "" {
+		productID = rfq.ProductID
+	}
+	if rfq.ProductID != "" && productID != rfq.ProductID {
+		return nil, errors.New("quote must be for the requested product")
+	}
+
+	product, err := ss.PostgresRepository.GetProduct(productID, constant.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("product does not exist")
+		}
+		logger.Logger.Errorf("[SubmitQuote]Failed to get product: %v", err)
+		return nil, errors.New("unable to submit quote, please try again later")
+	}
+	if product.SupplierID != user.ID {
+		return nil, errors.New("product does not exist")
+	}
+	if product.ApprovalStatus != constant.Approved || product.Status != constant.Active {
+		return nil, errors.New("product is not available for sale")
+	}
+	if product.HasVariants() {
+		variant, ok := product.Variant(req.VariantID)
+		if !ok || variant.Status != constant.Active {
+			return nil, errors.New("please choose a valid variant of this product")
+		}
+	} else if req.VariantID != "" {
+		return nil, errors.New("this product has no variants")
+	}
+	if rfq.ProductID == "" && product.Category != rfq.Category {
+		return nil, fmt.Errorf("product must be in the %s category", rfq.Category)
+	}
+	if product.PaymentTerms != "" && product.PaymentTerms != rfq.PaymentTerms {
+		return nil, fmt.Errorf("product can only be paid for by %s", product.PaymentTerms)
+	}
+
+	quantity := req.Quantity
+	if quantity == 0 {
+		quantity = rfq.Quantity
+	}
+	if quantity < 0 {
+		return nil, errors.New("quantity must be greater than zero")
+	}
+	expiresAt, err := utils.ParseQuoteExpiry(req.ExpiresAt, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	quote := &models.Quote{
+		RfqID:         rfq.ID,
+		SupplierID:    user.ID,
+		ProductID:     product.ID,
+		VariantID:     req.VariantID,
+		UnitPrice:     req.UnitPrice,
+		Quantity:      quantity,
+		DeliveryTerms: req.DeliveryTerms,
+		ExpiresAt:     expiresAt,
+		Status:        constant.Pending,
+		AwaitingRole:  enum.Buyer,
+		Offers: []models.QuoteOffer{{
+			OfferedBy:     user.ID,
+			OfferedByRole: user.Role,
+			UnitPrice:     req.UnitPrice,
+			Quantity:      quantity,
+			ExpiresAt:     expiresAt,
+			Note:          req.Note,
+		}},
+	}
+	if err = ss.PostgresRepository.CreateQuote(quote); err != nil {
+		return nil, errors.New("unable to submit quote, please try again later")
+	}
+
+	ss.notifyRfqBuyer(rfq, "You have a new quote",
+		fmt.Sprintf("%s has quoted %s %d per %s for %d units of %s. The quote expires on %s.",
+			user.BusinessName, constant.NGN, quote.UnitPrice, product.Unit, quote.Quantity, product.Name, expiresAt.Format("02 Jan 2006 15:04")))
+	return quote, nil
+}
+
+func (ss *ServiceSupplier) GetQuotes(pm *models.PaginationMetadata, status string, user *models.User) ([]models.Quote, *models.PaginationMetadata, error) {
+	quotes, paginationMetaData, err := ss.PostgresRepository.GetSupplierQuotes(pm, user.ID, status)
+	if err != nil {
+		logger.Logger.Errorf("[GetQuotes]Failed to get supplier quotes: %v", err)
+		return nil, pm, errors.New("unable to get quotes")
+	}
+	return quotes, paginationMetaData, nil
+}
+
+// getQuote fetches one of the supplier's quotes that is waiting on the supplier
+func (ss *ServiceSupplier) getQuote(id string, user *models.User) (*models.Quote, error) {
+	quote, err := ss.PostgresRepository.GetQuote(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("quote does not exist")
+		}
+		return nil, errors.New("unable to get quote")
+	}
+	if quote.SupplierID != user.ID || quote.Rfq == nil {
+		return nil, errors.New("quote does not exist")
+	}
+
+	if quote.Status != constant.Pending {
+		return nil, fmt.Errorf("quote is %s", quote.Status)
+	}
+	if quote.AwaitingRole != enum.Supplier {
+		return nil, errors.New("quote is waiting on the buyer")
+	}
+	return quote, nil
+}
+
+// CounterQuote answers a buyer's counter-offer with new terms
+func (ss *ServiceSupplier) CounterQuote(id string, req models.CounterOfferRequest, user *models.User) (*models.Quote, error) {
+	quote, err := ss.getQuote(id, user)
+	if err != nil {
+		return nil, err
+	}
+
+	offer, err := utils.BuildCounterOffer(quote, req, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = ss.PostgresRepository.CounterQuote(quote, offer, enum.Buyer); err != nil {
+		if errors.Is(err, postgresrepository.ErrQuoteChanged) {
+			return nil, err
+		}
+		return nil, errors.New("unable to counter quote, please try again later")
+	}
+
+	ss.notifyRfqBuyer(quote.Rfq, "You have a counter-offer",
+		fmt.Sprintf("%s has countered with %s %d per %s for %d units of %s.",
+			user.BusinessName, constant.NGN, offer.UnitPrice, quote.Product.Unit, offer.Quantity, quote.Product.Name))
+	return quote, nil
+}
+
+// AcceptQuote agrees to the buyer's counter-offer and places the order at that price
+func (ss *ServiceSupplier) AcceptQuote(id string, user *models.User) (*models.Order, error) {
+	quote, err := ss.getQuote(id, user)
+	if err != nil {
+		return nil, err
+	}
+
+	order := utils.BuildQuoteOrder(quote)
+	if err = ss.PostgresRepository.AcceptQuote(quote, order); err != nil {
+		if errors.Is(err, postgresrepository.ErrQuoteChanged) || errors.Is(err, postgresrepository.ErrInsufficientStock) ||
+			errors.Is(err, postgresrepository.ErrProductNotAvailable) {
+			return nil, err
+		}
+		return nil, errors.New("unable to accept quote, please try again later")
+	}
+
+	message := fmt.Sprintf("%s has accepted your offer for %d units of %s. Order %s has been placed.",
+		user.BusinessName, quote.Quantity, quote.Product.Name, order.Reference)
+	if order.PaymentTerms == constant.Prepayment {
+		message += " Please pay for the order from your orders page."
+	}
+	ss.notifyRfqBuyer(quote.Rfq, "Your offer has been accepted", message)
+	return order, nil
+}
+
+func (ss *ServiceSupplier) RejectQuote(id string, user *models.User) error {
+	quote, err := ss.getQuote(id, user)
+	if err != nil {
+		return err
+	}
+
+	if err = ss.PostgresRepository.RejectQuote(quote); err != nil {
+		if errors.Is(err, postgresrepository.ErrQuoteChanged) {
+			return err
+		}
+		return errors.New("unable to reject quote, please try again later")
+	}
+
+	ss.notifyRfqBuyer(quote.Rfq, "Your offer has been declined",
+		fmt.Sprintf("%s has declined your offer for %s.", user.BusinessName, quote.Product.Name))
+	return nil
+}
+
+// notifyRfqBuyer emails the buyer about a change on their RFQ
+func (ss *ServiceSupplier) notifyRfqBuyer(rfq *models.Rfq, title, message string) {
+	body := utils.BuildRfqUpdateEmail(rfq.Buyer.Name, rfq.Reference, title, message)
+
+	err := ss.EmailService.Send(rfq.Buyer.Email, fmt.Sprintf("%s - %s", title, rfq.Reference), body)
+	if err != nil {
+		logger.Logger.Errorf("[notifyRfqBuyer]Failed to send email for rfq %s: %v", rfq.Reference, err)
+	}
+}
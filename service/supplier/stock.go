@@ -0,0 +1,129 @@
+package supplier
+
+import (
+	"bambamload/constant"
+	"bambamload/logger"
+	"bambamload/models"
+	"bambamload/service/postgresrepository"
+	"bambamload/utils"
+	"errors"
+	"fmt"
+)
+
+// AdjustStock posts a movement to the stock ledger of one of the supplier's products
+func (ss *ServiceSupplier) AdjustStock(productID string, req models.StockAdjustmentRequest, user *models.User) (*models.StockMovement, error) {
+	product, err := ss.getOwnProduct(productID, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if product.HasVariants() {
+		if _, ok := product.Variant(req.VariantID); !ok {
+			return nil, errors.New("stock of this product is held on its variants, please choose a valid variant")
+		}
+	} else if req.VariantID != "" {
+		return nil, errors.New("this product has no variants")
+	}
+
+	quantity := req.Quantity
+	switch req.Type {
+	case constant.Receipt, constant.Return:
+		if quantity <= 0 {
+			return nil, errors.New("quantity must be greater than zero")
+		}
+	case constant.Damage:
+		if quantity <= 0 {
+			return nil, errors.New("quantity must be greater than zero")
+		}
+		quantity = -quantity
+	case constant.Adjustment:
+		if quantity == 0 {
+			return nil, errors.New("quantity cannot be zero")
+		}
+	default:
+		return nil, errors.New("type can only be receipt/adjustment/return/damage")
+	}
+
+	if (req.Type == constant.Adjustment || req.Type == constant.Damage) && req.Reason == "" {
+		return nil, errors.New("reason cannot be empty")
+	}
+
+	movement := &models.StockMovement{
+		ProductID: product.ID,
+		VariantID: req.VariantID,
+		Type:      req.Type,
+		Quantity:  quantity,
+		Reason:    req.Reason,
+		CreatedBy: user.ID,
+	}
+
+	err = ss.PostgresRepository.PostStockMovement(nil, movement)
+	if err != nil {
+		if errors.Is(err, postgresrepository.ErrInsufficientStock) {
+			return nil, errors.New("stock cannot go below zero")
+		}
+		logger.Logger.Errorf("[AdjustStock]Failed to post stock movement for product %s: %v", product.ID, err)
+		return nil, errors.New("unable to adjust stock, please try again later")
+	}
+	return movement, nil
+}
+
+func (ss *ServiceSupplier) GetStockMovements(pm *models.PaginationMetadata, productID, variantID string, user *models.User) ([]models.StockMovement, *models.PaginationMetadata, error) {
+	product, err := ss.getOwnProduct(productID, user)
+	if err != nil {
+		return nil, pm, err
+	}
+
+	movements, paginationMeta, err := ss.PostgresRepository.GetStockMovements(pm, product.ID, variantID)
+	if err != nil {
+		return nil, pm, errors.New("unable to get stock movements, please try again later")
+	}
+	return movements, paginationMeta, nil
+}
+
+// SendStockAlerts tells suppliers about products that have dropped to their low stock alert level or run out.
+// Each crossing is announced once; a product that recovers is quietly reset so its next crossing is announced again.
+func (ss *ServiceSupplier) SendStockAlerts() {
+	alerts, err := ss.PostgresRepository.GetStockAlerts()
+	if err != nil {
+		return
+	}
+
+	for _, alert := range alerts {
+		item := alert.ProductName
+		if alert.VariantName != "" {
+			item = fmt.Sprintf("%s (%s)", alert.ProductName, alert.VariantName)
+		}
+
+		var title, message string
+		switch alert.State {
+		case constant.OutOfStock:
+			title = "Product out of stock"
+			message = fmt.Sprintf("%s is out of stock and can no longer be ordered until you restock it.", item)
+		case constant.LowStock:
+			title = "Product running low"
+			message = fmt.Sprintf("%s is down to %d units, at or below your alert level of %d.", item, alert.Stock, alert.AlertLevel)
+		}
+
+		var notification *models.Notification
+		if title != "" {
+			notification = &models.Notification{
+				UserID:    alert.SupplierID,
+				Type:      alert.State,
+				Title:     title,
+				Message:   message,
+				Reference: alert.ProductID,
+			}
+		}
+
+		recorded, err := ss.PostgresRepository.RecordStockAlert(alert, notification)
+		if err != nil || !recorded || notification == nil {
+			continue
+		}
+
+		body := utils.BuildStockAlertEmail(alert.SupplierName, item, title, message)
+		if err = ss.EmailService.Send(alert.SupplierEmail, fmt.Sprintf("%s - %s", title, item), body); err != nil {
+			logger.Logger.Errorf("[SendStockAlerts]Failed to send %s email for product %s: %v", alert.State, alert.ProductID, err)
+		}
+	}
+}
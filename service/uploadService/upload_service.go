@@ -3,9 +3,12 @@ package uploadService
 import (
 	"bambamload/logger"
 	"bambamload/utils"
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"mime/multipart"
+	"net/http"
 	"os"
 	"time"
 
@@ -48,6 +51,33 @@ func (us *UploadService) GeneratePresignedDownloadURL(ctx context.Context, clien
 
 func (us *UploadService) Upload(file multipart.File, fileName string) (string, error) {
 	defer file.Close()
+	return us.upload(file, fileName)
+}
+
+// UploadBytes uploads generated content such as documents built in memory
+func (us *UploadService) UploadBytes(data []byte, fileName string) (string, error) {
+	return us.upload(bytes.NewReader(data), fileName)
+}
+
+// Download fetches an uploaded file back through its download URL
+func (us *UploadService) Download(url string) ([]byte, error) {
+	client := &http.Client{Timeout: time.Minute}
+
+	resp, err := client.Get(url)
+	if err != nil {
+		logger.Logger.Errorf("[Download]cannot download object: %v", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Logger.Errorf("[Download]cannot download object, status: %d", resp.StatusCode)
+		return nil, fmt.Errorf("cannot download object, status: %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func (us *UploadService) upload(body io.Reader, fileName string) (string, error) {
 	ctx := context.Background()
 	// Custom resolver for B2 endpoint
 	resolver := aws.EndpointResolverWithOptionsFunc(func(service, region string, options ...interface{}) (aws.Endpoint, error) {
@@ -88,7 +118,7 @@ func (us *UploadService) Upload(file multipart.File, fileName string) (string, e
 	result, err := uploader.Upload(ctx, &s3.PutObjectInput{
 		Bucket:      aws.String(us.BucketName),
 		Key:         aws.String(fmt.Sprintf("%s/%s", name, time.Now().Format("20060102150405"))),
-		Body:        file,
+		Body:        body,
 		ContentType: aws.String(extType),
 	})
 	if err != nil {
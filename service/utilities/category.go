@@ -0,0 +1,16 @@
+package utilities
+
+import (
+	"bambamload/models"
+	"bambamload/utils"
+	"errors"
+)
+
+// GetCategoryTree returns the category tree, or only the categories products can currently be filed under
+func (su ServiceUtilities) GetCategoryTree(activeOnly bool) ([]models.Category, error) {
+	categories, err := su.PostgresRepository.GetCategories(activeOnly)
+	if err != nil {
+		return nil, errors.New("unable to get categories, please try again later")
+	}
+	return utils.BuildCategoryTree(categories), nil
+}
@@ -0,0 +1,66 @@
+package utilities
+
+import (
+	"bambamload/constant"
+	"bambamload/logger"
+	"bambamload/models"
+	"bambamload/utils"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// documentURLMaxAge is kept under the upload service's presigned URL lifetime so a stored link is never stale
+const documentURLMaxAge = 5 * 24 * time.Hour
+
+// GetOrderDocument returns the invoice or receipt for an order, generating and uploading the PDF when needed
+func (su ServiceUtilities) GetOrderDocument(order *models.Order, docType string) (*models.OrderDocument, error) {
+	if docType == constant.Receipt && order.PaymentStatus != constant.Paid {
+		return nil, errors.New("a receipt is only available once the order has been paid for")
+	}
+
+	//a stored document is reused until its link nears expiry or the order changes after it was generated
+	existing, err := su.PostgresRepository.GetOrderDocument(order.ID, docType)
+	if err == nil && time.Since(existing.UpdatedAt) < documentURLMaxAge && !order.UpdatedAt.After(existing.UpdatedAt) {
+		return existing, nil
+	}
+
+	prefix := "INV"
+	if docType == constant.Receipt {
+		prefix = "RCT"
+	}
+	number := fmt.Sprintf("%s-%s", prefix, strings.TrimPrefix(order.Reference, "ORD-"))
+
+	data, err := utils.BuildOrderDocumentPDF(models.OrderDocumentData{
+		Type:             docType,
+		Number:           number,
+		Order:            order,
+		VatRate:          constant.VatRate,
+		VatAmount:        utils.CalculateInclusiveVat(order.TotalAmount, constant.VatRate),
+		CommissionAmount: utils.CalculateCommission(order.TotalAmount, order.CommissionRate),
+	})
+	if err != nil {
+		logger.Logger.Errorf("[GetOrderDocument]build %s pdf error: %v", docType, err)
+		return nil, fmt.Errorf("unable to generate %s, please try again later", docType)
+	}
+
+	url, err := su.UploadService.UploadBytes(data, fmt.Sprintf("%s.pdf", number))
+	if err != nil {
+		logger.Logger.Errorf("[GetOrderDocument]upload %s error: %v", docType, err)
+		return nil, fmt.Errorf("unable to generate %s, please try again later", docType)
+	}
+
+	document := &models.OrderDocument{
+		OrderID:  order.ID,
+		Type:     docType,
+		Number:   number,
+		FileURL:  url,
+		FileType: ".pdf",
+	}
+	if err = su.PostgresRepository.SaveOrderDocument(document); err != nil {
+		logger.Logger.Errorf("[GetOrderDocument]save %s error: %v", docType, err)
+	}
+
+	return document, nil
+}
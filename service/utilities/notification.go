@@ -0,0 +1,22 @@
+package utilities
+
+import (
+	"bambamload/models"
+	"errors"
+)
+
+func (su ServiceUtilities) GetNotifications(pm *models.PaginationMetadata, unreadOnly bool, user *models.User) ([]models.Notification, *models.PaginationMetadata, error) {
+	notifications, paginationMetaData, err := su.PostgresRepository.GetNotifications(pm, user.ID, unreadOnly)
+	if err != nil {
+		return nil, pm, errors.New("unable to get notifications, please try again later")
+	}
+	return notifications, paginationMetaData, nil
+}
+
+// MarkNotificationsRead marks the user's notifications as read, all of them when no ids are given
+func (su ServiceUtilities) MarkNotificationsRead(ids []string, user *models.User) error {
+	if err := su.PostgresRepository.MarkNotificationsRead(user.ID, ids); err != nil {
+		return errors.New("unable to update notifications, please try again later")
+	}
+	return nil
+}
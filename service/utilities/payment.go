@@ -0,0 +1,69 @@
+package utilities
+
+import (
+	"bambamload/constant"
+	"bambamload/logger"
+	"bambamload/models"
+	"bambamload/service/paymentService"
+	"bambamload/service/postgresrepository"
+	"encoding/json"
+	"errors"
+)
+
+var ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+
+// HandlePaymentWebhook verifies a signed provider webhook and applies the payment outcome it reports.
+// The payment is re-verified with the provider rather than trusting the webhook body.
+func (su ServiceUtilities) HandlePaymentWebhook(payload []byte, signature string) error {
+	if su.PaymentProvider == nil {
+		return paymentService.ErrPaymentsUnavailable
+	}
+
+	if !su.PaymentProvider.VerifyWebhookSignature(payload, signature) {
+		return ErrInvalidWebhookSignature
+	}
+
+	var event models.PaymentWebhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		logger.Logger.Errorf("[HandlePaymentWebhook]unmarshal error: %v", err)
+		return errors.New("invalid webhook payload")
+	}
+
+	switch event.Event {
+	case constant.ChargeSuccess, constant.ChargeFailed:
+		verification, err := su.PaymentProvider.Verify(event.Reference)
+		if err != nil {
+			logger.Logger.Errorf("[HandlePaymentWebhook]provider verify error for %s: %v", event.Reference, err)
+			return errors.New("unable to verify payment")
+		}
+
+		if _, err = su.PostgresRepository.ApplyPaymentVerification(verification); err != nil {
+			//retrying a duplicate payment would never succeed, so it is acknowledged and left for a refund
+			if errors.Is(err, postgresrepository.ErrCheckoutAlreadyPaid) {
+				logger.Logger.Errorf("[HandlePaymentWebhook]payment %s is a second payment for its checkout and needs a refund", event.Reference)
+				return nil
+			}
+			return errors.New("unable to apply payment")
+		}
+		su.ProcessPaymentRefunds(event.Reference)
+	default:
+		logger.Logger.Infof("[HandlePaymentWebhook]ignoring event %s for %s", event.Event, event.Reference)
+	}
+
+	return nil
+}
+
+// SimulateFakePayment completes a payment on the fake provider and feeds the resulting signed webhook back in
+func (su ServiceUtilities) SimulateFakePayment(reference string, success bool) error {
+	fakeProvider, ok := su.PaymentProvider.(*paymentService.FakeProvider)
+	if !ok {
+		return errors.New("payment simulation is only available with the fake provider")
+	}
+
+	payload, signature, err := fakeProvider.SimulatePayment(reference, success)
+	if err != nil {
+		return err
+	}
+
+	return su.HandlePaymentWebhook(payload, signature)
+}
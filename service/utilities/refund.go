@@ -0,0 +1,60 @@
+package utilities
+
+import (
+	"bambamload/constant"
+	"bambamload/logger"
+	"bambamload/models"
+	"bambamload/service/paymentService"
+	"errors"
+)
+
+// ProcessRefund sends a provider refund to the payment provider and records the outcome.
+// Failed refunds keep their failure reason and can be processed again. Wallet refunds are already settled.
+func (su ServiceUtilities) ProcessRefund(refund *models.Refund) error {
+	if refund.Method != constant.Provider {
+		return nil
+	}
+	//the refund stays pending and can be retried once a provider is configured
+	if su.PaymentProvider == nil {
+		return paymentService.ErrPaymentsUnavailable
+	}
+
+	claimed, err := su.PostgresRepository.ClaimRefund(refund.ID)
+	if err != nil {
+		logger.Logger.Errorf("[ProcessRefund]claim refund %s error: %v", refund.Reference, err)
+		return errors.New("unable to process refund, please try again later")
+	}
+	if !claimed {
+		return errors.New("refund has already been processed or is being processed")
+	}
+
+	result, err := su.PaymentProvider.Refund(refund.PaymentReference, refund.Amount)
+	if err != nil {
+		logger.Logger.Errorf("[ProcessRefund]provider refund error for %s: %v", refund.Reference, err)
+
+		if err = su.PostgresRepository.CompleteRefund(refund, nil, err.Error()); err != nil {
+			return errors.New("unable to process refund, please try again later")
+		}
+		return errors.New("the payment provider could not process the refund, it can be retried")
+	}
+
+	if err = su.PostgresRepository.CompleteRefund(refund, result, ""); err != nil {
+		return errors.New("refund was paid out but could not be recorded, please contact support")
+	}
+	return nil
+}
+
+// ProcessPaymentRefunds sends the pending refunds recorded against a payment, such as those for orders closed
+// while it was in progress. Refunds that fail are left for an admin to retry.
+func (su ServiceUtilities) ProcessPaymentRefunds(paymentReference string) {
+	refunds, err := su.PostgresRepository.GetPendingPaymentRefunds(paymentReference)
+	if err != nil {
+		return
+	}
+
+	for i := range refunds {
+		if err = su.ProcessRefund(&refunds[i]); err != nil {
+			logger.Logger.Errorf("[ProcessPaymentRefunds]process refund %s error: %v", refunds[i].Reference, err)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"bambamload/middleware"
 	"bambamload/models"
 	"bambamload/service/email"
+	"bambamload/service/paymentService"
 	"bambamload/service/postgresrepository"
 	"bambamload/service/redisService"
 	"bambamload/service/uploadService"
@@ -27,14 +28,16 @@ type ServiceUtilities struct {
 	PostgresRepository *postgresrepository.PostgresRepository
 	EmailService       email.Email
 	UploadService      *uploadService.UploadService
+	PaymentProvider    paymentService.PaymentProvider
 }
 
-func NewServiceUtilities(redisService redisService.RedisService, postgresRepository *postgresrepository.PostgresRepository, emailService email.Email, uploadService *uploadService.UploadService) *ServiceUtilities {
+func NewServiceUtilities(redisService redisService.RedisService, postgresRepository *postgresrepository.PostgresRepository, emailService email.Email, uploadService *uploadService.UploadService, paymentProvider paymentService.PaymentProvider) *ServiceUtilities {
 	return &ServiceUtilities{
 		RedisService:       redisService,
 		PostgresRepository: postgresRepository,
 		EmailService:       emailService,
 		UploadService:      uploadService,
+		PaymentProvider:    paymentProvider,
 	}
 }
 
@@ -225,12 +228,66 @@ func (su ServiceUtilities) VerifyOtp(action, otp, email string, value any) error
 
 		return nil
 
+	case constant.DeliveryOtp:
+		order, ok := value.(*models.Order)
+		if !ok {
+			return errors.New("invalid order")
+		}
+		if os.Getenv(constant.AppEnv) == constant.Development && otp == constant.DefaultOtp {
+			return nil
+		}
+
+		ctx := context.Background()
+		key := fmt.Sprintf("%s:%s", constant.DeliveryOtp, order.Reference)
+		attemptKey := fmt.Sprintf("%s:%s", constant.DeliveryOtpAttempts, order.Reference)
+		lockoutKey := fmt.Sprintf("%s:%s", constant.DeliveryOtpLockouts, order.Reference)
+
+		// Check if delivery confirmation is locked for this order
+		if _, err := su.RedisService.GetRedisClient().Get(ctx, lockoutKey).Result(); err == nil {
+			return errors.New("too many invalid delivery codes. Try again in 30 minutes with a new code from the buyer")
+		}
+
+		var existingOtp string
+		err := su.RedisService.GetValue(key, &existingOtp)
+		if err != nil {
+			if errors.Is(err, redis.Nil) { //nolint:typecheck
+				logger.Logger.Errorf("[VerifyOtp]expired delivery otp: %v", err)
+				return errors.New("delivery code is expired, ask the buyer to request a new one")
+			}
+			logger.Logger.Errorf("[VerifyOtp]redis get error: %v", err)
+			return err
+		}
+
+		if existingOtp != otp {
+			// Increment failed attempts
+			attempts, _ := su.RedisService.GetRedisClient().Incr(ctx, attemptKey).Result()
+
+			// Set expiry
+			if attempts == 1 {
+				su.RedisService.GetRedisClient().Expire(ctx, attemptKey, 30*time.Minute)
+			}
+
+			// Burn the code and lock the order if attempts >= 5
+			if attempts >= 5 {
+				su.RedisService.GetRedisClient().Set(ctx, lockoutKey, "locked", 30*time.Minute)
+				su.RedisService.GetRedisClient().Del(ctx, attemptKey, key)
+				return errors.New("too many invalid delivery codes. Try again in 30 minutes with a new code from the buyer")
+			}
+
+			return fmt.Errorf("invalid delivery confirmation code. %v of 5 attempts used", attempts)
+		}
+
+		return nil
+
 	default:
 		return errors.New("invalid action")
 	}
 }
 
-func (su ServiceUtilities) SendOtp(action string, email string) error {
+// deliveryOtpTTL keeps a delivery code valid for a day of driving, the buyer can request a new one after that
+const deliveryOtpTTL = 24 * 60 * 60
+
+func (su ServiceUtilities) SendOtp(action string, email string, value any) error {
 	otp, err := utils.GenerateOTP(6)
 	if err != nil {
 		logger.Logger.Errorf("[SendOtp]otp generation failed: %v", err)
@@ -296,6 +353,32 @@ func (su ServiceUtilities) SendOtp(action string, email string) error {
 		_ = su.EmailService.Send(email, "Forgot Password Verification Code", message)
 
 		return nil
+
+	case constant.DeliveryOtp:
+		order, ok := value.(*models.Order)
+		if !ok {
+			return errors.New("invalid action")
+		}
+		key := fmt.Sprintf("%s:%s", constant.DeliveryOtp, order.Reference)
+
+		err = su.RedisService.SetValue(key, otp, deliveryOtpTTL)
+		if err != nil {
+			logger.Logger.Errorf("[DeliveryOtp]redis set failed: %v", err)
+			return err
+		}
+
+		message := fmt.Sprintf(
+			"Your order is on its way.\n\nYour delivery confirmation code is: %s\n\nThis code will expire in %d hours. Only share it with the driver once you have received your goods and paid %s %d.",
+			otp,
+			deliveryOtpTTL/3600,
+			constant.NGN,
+			order.TotalAmount,
+		)
+		body := utils.BuildOrderUpdateEmail(order.Buyer.Name, order.Reference, "Delivery Confirmation Code", message)
+
+		_ = su.EmailService.Send(email, "Delivery Confirmation Code", body)
+		return nil
+
 	default:
 		return errors.New("invalid action")
 	}
@@ -0,0 +1,43 @@
+package utilities
+
+import (
+	"bambamload/constant"
+	"bambamload/logger"
+	"bambamload/models"
+	"errors"
+)
+
+func (su ServiceUtilities) GetWalletBalance(user *models.User) (*models.WalletBalance, error) {
+	wallet, err := su.PostgresRepository.GetOrCreateWallet(user.ID, constant.User)
+	if err != nil {
+		logger.Logger.Errorf("[GetWalletBalance]get wallet error: %v", err)
+		return nil, errors.New("unable to get wallet, please try again later")
+	}
+
+	balance, err := su.PostgresRepository.GetWalletBalance(wallet.ID)
+	if err != nil {
+		logger.Logger.Errorf("[GetWalletBalance]get balance error: %v", err)
+		return nil, errors.New("unable to get wallet balance, please try again later")
+	}
+
+	return &models.WalletBalance{
+		Wallet:   wallet,
+		Balance:  balance,
+		Currency: wallet.Currency,
+	}, nil
+}
+
+func (su ServiceUtilities) GetWalletStatement(pm *models.PaginationMetadata, user *models.User) ([]models.LedgerEntry, *models.PaginationMetadata, error) {
+	wallet, err := su.PostgresRepository.GetOrCreateWallet(user.ID, constant.User)
+	if err != nil {
+		logger.Logger.Errorf("[GetWalletStatement]get wallet error: %v", err)
+		return nil, pm, errors.New("unable to get wallet, please try again later")
+	}
+
+	entries, paginationMetaData, err := su.PostgresRepository.GetWalletStatement(pm, wallet.ID)
+	if err != nil {
+		logger.Logger.Errorf("[GetWalletStatement]get statement error: %v", err)
+		return nil, pm, errors.New("unable to get wallet statement, please try again later")
+	}
+	return entries, paginationMetaData, nil
+}
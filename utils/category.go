@@ -0,0 +1,22 @@
+package utils
+
+import "bambamload/models"
+
+// BuildCategoryTree nests a flat list of categories under their parents, keeping the order of the list.
+// Categories whose parent is not in the list are left out.
+func BuildCategoryTree(categories []models.Category) []models.Category {
+	children := make(map[string][]models.Category)
+	for _, category := range categories {
+		children[category.ParentID] = append(children[category.ParentID], category)
+	}
+
+	var attach func(parentID string) []models.Category
+	attach = func(parentID string) []models.Category {
+		nodes := children[parentID]
+		for i := range nodes {
+			nodes[i].Children = attach(nodes[i].ID)
+		}
+		return nodes
+	}
+	return attach("")
+}
@@ -1,6 +1,9 @@
 package utils
 
-import "strings"
+import (
+	"html"
+	"strings"
+)
 
 func BuildSupplierInviteEmail(supplierName, signupLink, invitationMessage string) string {
 	var b strings.Builder
@@ -73,3 +76,59 @@ func BuildSupplierInviteEmail(supplierName, signupLink, invitationMessage string
 
 	return b.String()
 }
+
+func BuildOrderUpdateEmail(recipientName, orderReference, title, message string) string {
+	return buildUpdateEmail(recipientName, "Order reference", orderReference, title, message)
+}
+
+func BuildRfqUpdateEmail(recipientName, rfqReference, title, message string) string {
+	return buildUpdateEmail(recipientName, "RFQ reference", rfqReference, title, message)
+}
+
+func BuildStockAlertEmail(recipientName, productName, title, message string) string {
+	return buildUpdateEmail(recipientName, "Product", productName, title, message)
+}
+
+// buildUpdateEmail escapes every value it is given, since messages carry text typed by buyers and suppliers
+func buildUpdateEmail(recipientName, referenceLabel, reference, title, message string) string {
+	var b strings.Builder
+
+	b.WriteString(`<!DOCTYPE html>
+<html>
+  <body style="font-family: Arial, sans-serif; background-color:#f9fafb; padding:20px;">
+    <div style="max-width:600px; margin:0 auto; background:#ffffff; padding:24px; border-radius:6px; color:#111827;">
+
+      <h2 style="margin-top:0;">`)
+	b.WriteString(html.EscapeString(title))
+	b.WriteString(`</h2>
+
+      <p>Dear `)
+	b.WriteString(html.EscapeString(recipientName))
+	b.WriteString(`,</p>
+
+      <p>
+        `)
+	b.WriteString(html.EscapeString(referenceLabel))
+	b.WriteString(`: <strong>`)
+	b.WriteString(html.EscapeString(reference))
+	b.WriteString(`</strong>
+      </p>
+
+      <p style="white-space:pre-line;">
+        `)
+	b.WriteString(html.EscapeString(message))
+
+	b.WriteString(`
+      </p>
+
+      <p style="margin-top:30px;">
+        Thank you for using Bambamload.<br/>
+        <strong>The Bambamload Team</strong>
+      </p>
+
+    </div>
+  </body>
+</html>`)
+
+	return b.String()
+}
@@ -146,3 +146,8 @@ func SplitFileName(filename string) (name string, ext string) {
 
 	return strings.TrimSuffix(filename, ext), ext
 }
+
+// ToKobo converts a naira amount to kobo
+func ToKobo(amount int64) int64 {
+	return amount * 100
+}
@@ -0,0 +1,151 @@
+package utils
+
+import (
+	"bambamload/constant"
+	"bambamload/models"
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// CalculateInclusiveVat returns the VAT contained in a VAT-inclusive amount for a rate given in percent
+func CalculateInclusiveVat(amount int64, rate float64) int64 {
+	return int64(math.Round(float64(amount) * rate / (100 + rate)))
+}
+
+// FormatAmount renders an amount with thousands separators, e.g. NGN 1,250,000
+func FormatAmount(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := strconv.FormatInt(amount, 10)
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	return fmt.Sprintf("%s %s%s", constant.NGN, sign, b.String())
+}
+
+// BuildOrderDocumentPDF renders an order invoice or receipt as a PDF
+func BuildOrderDocumentPDF(data models.OrderDocumentData) ([]byte, error) {
+	order := data.Order
+	supplier := order.Supplier
+	buyer := order.Buyer
+
+	label := "Invoice"
+	if data.Type == constant.Receipt {
+		label = "Receipt"
+	}
+	title := strings.ToUpper(label)
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.AddPage()
+
+	// header
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.CellFormat(100, 10, supplier.BusinessName, "", 0, "L", false, 0, "")
+	pdf.CellFormat(80, 10, title, "", 1, "R", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", 9)
+	for _, line := range []string{
+		supplier.Address,
+		strings.Trim(strings.Join([]string{supplier.State, supplier.Country}, ", "), ", "),
+		supplier.Email,
+		supplier.PhoneNumber,
+	} {
+		if line != "" {
+			pdf.CellFormat(100, 5, line, "", 1, "L", false, 0, "")
+		}
+	}
+	pdf.Ln(4)
+
+	// document and buyer details
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(90, 6, "Billed To", "", 0, "L", false, 0, "")
+	pdf.CellFormat(90, 6, "Details", "", 1, "L", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", 9)
+	left := []string{buyer.Name, buyer.Email, buyer.PhoneNumber, order.DeliveryAddress}
+	right := []string{
+		fmt.Sprintf("%s No: %s", label, data.Number),
+		fmt.Sprintf("Order Ref: %s", order.Reference),
+		fmt.Sprintf("Order Date: %s", order.CreatedAt.Format("02 Jan 2006")),
+		fmt.Sprintf("Payment Terms: %s", strings.ReplaceAll(order.PaymentTerms, "_", " ")),
+		fmt.Sprintf("Payment Status: %s", order.PaymentStatus),
+	}
+	for i := 0; i < len(right); i++ {
+		l := ""
+		if i < len(left) {
+			l = left[i]
+		}
+		pdf.CellFormat(90, 5, l, "", 0, "L", false, 0, "")
+		pdf.CellFormat(90, 5, right[i], "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(6)
+
+	// line items
+	widths := []float64{80, 20, 20, 30, 30}
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.SetFillColor(243, 244, 246)
+	for i, heading := range []string{"Item", "Unit", "Qty", "Unit Price", "Amount"} {
+		align := "L"
+		if i >= 2 {
+			align = "R"
+		}
+		pdf.CellFormat(widths[i], 7, heading, "1", 0, align, true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont("Helvetica", "", 9)
+	for _, item := range order.OrderItems {
+		//cancelled units are refunded or never charged, so only the rest is billed
+		quantity := item.Quantity - item.CancelledQuantity
+		if quantity <= 0 {
+			continue
+		}
+		pdf.CellFormat(widths[0], 7, item.ProductName, "1", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[1], 7, item.Unit, "1", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[2], 7, strconv.FormatInt(quantity, 10), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[3], 7, FormatAmount(item.UnitPrice), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[4], 7, FormatAmount(item.UnitPrice*quantity), "1", 1, "R", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	// totals
+	totals := [][2]string{
+		{"Sub Total", FormatAmount(order.SubTotal)},
+		{fmt.Sprintf("VAT (%.1f%%, inclusive)", data.VatRate), FormatAmount(data.VatAmount)},
+		{"Total", FormatAmount(order.TotalAmount)},
+		{fmt.Sprintf("Platform Commission (%.2f%%)", order.CommissionRate), FormatAmount(data.CommissionAmount)},
+	}
+	for i, row := range totals {
+		style := ""
+		if i == 2 {
+			style = "B"
+		}
+		pdf.SetFont("Helvetica", style, 9)
+		pdf.CellFormat(130, 6, row[0], "", 0, "R", false, 0, "")
+		pdf.CellFormat(50, 6, row[1], "", 1, "R", false, 0, "")
+	}
+
+	pdf.Ln(8)
+	pdf.SetFont("Helvetica", "I", 8)
+	pdf.MultiCell(180, 4, "Platform commission is deducted from the supplier's payout and is not charged to the buyer. Generated by Bambamload.", "", "L", false)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
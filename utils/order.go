@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"bambamload/constant"
+	"bambamload/models"
+	"fmt"
+	"math"
+)
+
+// orderTransitions lists the statuses an order may move to from each status
+var orderTransitions = map[string][]string{
+	constant.Pending:   {constant.Confirmed, constant.Rejected, constant.Cancelled},
+	constant.Confirmed: {constant.InTransit, constant.Cancelled},
+	constant.InTransit: {constant.Delivered},
+	constant.Delivered: {constant.Completed},
+}
+
+// CanTransitionOrder reports whether an order may move from one status to another
+func CanTransitionOrder(from, to string) bool {
+	for _, status := range orderTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateOrderTransition returns an error describing why an order cannot move between the statuses
+func ValidateOrderTransition(from, to string) error {
+	if !CanTransitionOrder(from, to) {
+		return fmt.Errorf("order cannot be moved from %s to %s", from, to)
+	}
+	return nil
+}
+
+// CalculateCommission returns the platform fee on an amount for a commission rate given in percent
+func CalculateCommission(amount int64, rate float32) int64 {
+	return int64(math.Round(float64(amount) * float64(rate) / 100))
+}
+
+// BuildQuoteOrder turns an accepted quote into a single line order at the agreed price, on the terms of its RFQ.
+// The quote must be loaded with its RFQ and product with its variants; the commission rate is set when it is placed.
+func BuildQuoteOrder(quote *models.Quote) *models.Order {
+	lineTotal := quote.UnitPrice * quote.Quantity
+
+	item := models.OrderItem{
+		ProductID:   quote.ProductID,
+		ProductName: quote.Product.Name,
+		Unit:        quote.Product.Unit,
+		Quantity:    quote.Quantity,
+		UnitPrice:   quote.UnitPrice,
+		LineTotal:   lineTotal,
+	}
+	if variant, ok := quote.Product.Variant(quote.VariantID); ok {
+		item.VariantID, item.VariantName, item.SKU = variant.ID, variant.Name, variant.SKU
+	}
+
+	return &models.Order{
+		Reference:         GenerateReference("ORD"),
+		CheckoutReference: GenerateReference("CHK"),
+		QuoteID:           quote.ID,
+		BuyerID:           quote.Rfq.BuyerID,
+		SupplierID:        quote.SupplierID,
+		Status:            constant.Pending,
+		PaymentTerms:      quote.Rfq.PaymentTerms,
+		PaymentStatus:     constant.Unpaid,
+		FulfilmentType:    quote.Rfq.FulfilmentType,
+		DeliveryAddress:   quote.Rfq.DeliveryAddress,
+		Note:              quote.Rfq.Note,
+		SubTotal:          lineTotal,
+		TotalAmount:       lineTotal,
+		OrderItems:        []models.OrderItem{item},
+	}
+}
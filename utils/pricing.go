@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"bambamload/models"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ValidatePriceTiers sorts the tiers by quantity and checks that their bands do not overlap.
+// Only the highest band may be open-ended.
+func ValidatePriceTiers(tiers []models.PriceTier) error {
+	sort.Slice(tiers, func(i, j int) bool {
+		return tiers[i].MinQuantity < tiers[j].MinQuantity
+	})
+
+	for i, tier := range tiers {
+		if tier.MinQuantity <= 0 {
+			return errors.New("price tier min_quantity must be greater than zero")
+		}
+		if tier.UnitPrice <= 0 {
+			return errors.New("price tier unit_price must be greater than zero")
+		}
+		if tier.MaxQuantity != 0 && tier.MaxQuantity < tier.MinQuantity {
+			return fmt.Errorf("price tier starting at %d has a max_quantity below its min_quantity", tier.MinQuantity)
+		}
+		if i == 0 {
+			continue
+		}
+
+		previous := tiers[i-1]
+		if previous.MaxQuantity == 0 || previous.MaxQuantity >= tier.MinQuantity {
+			return fmt.Errorf("price tiers starting at %d and %d overlap", previous.MinQuantity, tier.MinQuantity)
+		}
+	}
+	return nil
+}
+
+// ValidateProductVariants checks the variants being added to a product, including that their SKUs are unique among themselves
+func ValidateProductVariants(variants []models.ProductVariant) error {
+	skus := make(map[string]bool, len(variants))
+
+	for _, variant := range variants {
+		if variant.SKU == "" {
+			return errors.New("variant sku cannot be empty")
+		}
+		if skus[variant.SKU] {
+			return fmt.Errorf("sku %s is used more than once", variant.SKU)
+		}
+		skus[variant.SKU] = true
+
+		if variant.Name == "" {
+			return errors.New("variant name cannot be empty")
+		}
+		if variant.UnitPrice <= 0 {
+			return fmt.Errorf("unit price of %s must be greater than zero", variant.SKU)
+		}
+		if variant.CurrentStockQuantity < 0 || variant.LowStockAlertLevel < 0 {
+			return fmt.Errorf("stock levels of %s cannot be negative", variant.SKU)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"bambamload/models"
+	"errors"
+	"time"
+)
+
+// ParseQuoteExpiry reads an RFC3339 expiry, falling back to current when value is empty. The expiry must be in the future.
+func ParseQuoteExpiry(value string, current time.Time) (time.Time, error) {
+	expiresAt := current
+	if value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return time.Time{}, errors.New("expires_at must be an RFC3339 timestamp")
+		}
+		expiresAt = parsed
+	}
+
+	if !expiresAt.After(time.Now()) {
+		return time.Time{}, errors.New("expires_at must be in the future")
+	}
+	return expiresAt, nil
+}
+
+// BuildCounterOffer validates a counter-offer on a quote. A missing quantity or expiry keeps the quote's current one.
+func BuildCounterOffer(quote *models.Quote, req models.CounterOfferRequest, user *models.User) (*models.QuoteOffer, error) {
+	if req.UnitPrice <= 0 {
+		return nil, errors.New("unit price must be greater than zero")
+	}
+
+	quantity := req.Quantity
+	if quantity == 0 {
+		quantity = quote.Quantity
+	}
+	if quantity < 0 {
+		return nil, errors.New("quantity must be greater than zero")
+	}
+
+	expiresAt, err := ParseQuoteExpiry(req.ExpiresAt, quote.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.QuoteOffer{
+		QuoteID:       quote.ID,
+		OfferedBy:     user.ID,
+		OfferedByRole: user.Role,
+		UnitPrice:     req.UnitPrice,
+		Quantity:      quantity,
+		ExpiresAt:     expiresAt,
+		Note:          req.Note,
+	}, nil
+}
@@ -0,0 +1,145 @@
+package utils
+
+import (
+	"bambamload/constant"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ReadSpreadsheet returns the rows of a CSV file, or of the first sheet of an XLSX workbook
+func ReadSpreadsheet(data []byte, ext string) ([][]string, error) {
+	switch strings.ToLower(ext) {
+	case constant.CSV:
+		reader := csv.NewReader(bytes.NewReader(data))
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		return reader.ReadAll()
+
+	case constant.XLSX:
+		workbook, err := excelize.OpenReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer workbook.Close()
+
+		sheets := workbook.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, errors.New("workbook has no sheets")
+		}
+		return workbook.GetRows(sheets[0])
+
+	default:
+		return nil, errors.New("only csv and xlsx files are supported")
+	}
+}
+
+// SpreadsheetColumns maps the lower-cased, trimmed headings of a header row to their column index, ignoring
+// the byte order mark some spreadsheet tools write before the first heading
+func SpreadsheetColumns(header []string) map[string]int {
+	columns := make(map[string]int, len(header))
+	for i, heading := range header {
+		heading = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(heading, "\ufeff")))
+		if heading != "" {
+			columns[heading] = i
+		}
+	}
+	return columns
+}
+
+// BuildCSV renders rows as a CSV file
+func BuildCSV(rows [][]string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	writer := csv.NewWriter(&buf)
+	if err := writer.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SpreadsheetWriter writes rows one at a time to a CSV or XLSX file. Close must be called to finish the file.
+type SpreadsheetWriter interface {
+	WriteRow(values []interface{}) error
+	Close() error
+}
+
+// NewSpreadsheetWriter starts a CSV or XLSX file on w. CSV rows reach w as they are written; XLSX rows are
+// spooled by the stream writer and the workbook is written out on Close.
+func NewSpreadsheetWriter(w io.Writer, ext string) (SpreadsheetWriter, error) {
+	switch strings.ToLower(ext) {
+	case constant.CSV:
+		return &csvSheetWriter{writer: csv.NewWriter(w)}, nil
+
+	case constant.XLSX:
+		workbook := excelize.NewFile()
+		stream, err := workbook.NewStreamWriter(workbook.GetSheetList()[0])
+		if err != nil {
+			_ = workbook.Close()
+			return nil, err
+		}
+		return &xlsxSheetWriter{out: w, workbook: workbook, stream: stream}, nil
+
+	default:
+		return nil, errors.New("only csv and xlsx files are supported")
+	}
+}
+
+type csvSheetWriter struct {
+	writer *csv.Writer
+}
+
+func (c *csvSheetWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		if text, ok := value.(string); ok {
+			record[i] = escapeCSVFormula(text)
+			continue
+		}
+		record[i] = fmt.Sprint(value)
+	}
+	return c.writer.Write(record)
+}
+
+// escapeCSVFormula stops text typed by users from running as a formula when the file is opened in a spreadsheet
+func escapeCSVFormula(text string) string {
+	if text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
+
+func (c *csvSheetWriter) Close() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+type xlsxSheetWriter struct {
+	out      io.Writer
+	workbook *excelize.File
+	stream   *excelize.StreamWriter
+	rows     int
+}
+
+func (x *xlsxSheetWriter) WriteRow(values []interface{}) error {
+	x.rows++
+	cell, err := excelize.CoordinatesToCellName(1, x.rows)
+	if err != nil {
+		return err
+	}
+	return x.stream.SetRow(cell, values)
+}
+
+func (x *xlsxSheetWriter) Close() error {
+	defer x.workbook.Close()
+
+	if err := x.stream.Flush(); err != nil {
+		return err
+	}
+	return x.workbook.Write(x.out)
+}
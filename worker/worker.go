@@ -1 +1,44 @@
 package worker
+
+import (
+	"bambamload/constant"
+	"bambamload/logger"
+	"bambamload/service/redisService"
+	"bambamload/service/supplier"
+	"time"
+)
+
+const (
+	stockAlertInterval    = 15 * time.Minute
+	productImportInterval = 5 * time.Minute
+)
+
+type Worker struct {
+	RedisService    redisService.RedisService
+	SupplierService *supplier.ServiceSupplier
+}
+
+func NewWorker(redisService redisService.RedisService, supplierService *supplier.ServiceSupplier) *Worker {
+	return &Worker{
+		RedisService:    redisService,
+		SupplierService: supplierService,
+	}
+}
+
+// Start runs the background jobs on their schedules for the life of the process
+func (w *Worker) Start() {
+	go w.schedule(constant.StockAlertLock, stockAlertInterval, w.SupplierService.SendStockAlerts)
+	go w.schedule(constant.ProductImportLock, productImportInterval, w.SupplierService.ResumeProductImports)
+}
+
+// schedule runs a job every interval. The lock keeps the job to one instance at a time across the deployment.
+func (w *Worker) schedule(lockKey string, interval time.Duration, job func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		logger.Logger.Infof("running %s", lockKey)
+		w.RedisService.RunWithLock(lockKey, interval, job)
+		<-ticker.C
+	}
+}